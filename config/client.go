@@ -0,0 +1,262 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/kong/go-kong/kong"
+	"github.com/steadybit/extension-kong/v2/extmetrics"
+	"github.com/steadybit/extension-kong/v2/exttracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type cachedClient struct {
+	transport *http.Transport
+	client    *kong.Client
+}
+
+// clientKey identifies a client by the configuration of its instance and the workspace its calls are scoped to.
+type clientKey struct {
+	fingerprint string
+	workspace   string
+}
+
+var (
+	clientsMu sync.Mutex
+	clients   = map[clientKey]*cachedClient{}
+)
+
+// GetClient returns the Kong client of the instance. Clients are cached per instance configuration and workspace so that
+// connections to the admin API are kept alive and reused. As soon as the configuration of an instance changes,
+// a new client is built. Clients of configurations no longer in use are dropped once the instances get
+// replaced or an execution releases its pinned instance.
+func (i *Instance) GetClient(ctx context.Context) (*kong.Client, error) {
+	fingerprint, err := i.fingerprint()
+	if err != nil {
+		return nil, err
+	}
+
+	key := clientKey{fingerprint: fingerprint, workspace: i.workspace}
+	clientsMu.Lock()
+	cached, ok := clients[key]
+	clientsMu.Unlock()
+	if ok {
+		return cached.client, nil
+	}
+
+	// building a client may call the Konnect API, which must not block the clients of other instances
+	tlsConfig, err := i.TLS.clientConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := newTransport(tlsConfig)
+	client, err := i.newClient(ctx, fingerprint, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	if cached, ok := clients[key]; ok {
+		// another call built a client for the same instance in the meantime
+		transport.CloseIdleConnections()
+		return cached.client, nil
+	}
+	clients[key] = &cachedClient{
+		transport: transport,
+		client:    client,
+	}
+	return client, nil
+}
+
+// pruneClients drops the cached clients, and everything else cached by instance configuration, of all instance
+// configurations which are neither configured nor pinned.
+func pruneClients() {
+	inUse := map[string]bool{}
+	for _, instance := range instancesInUse() {
+		if fingerprint, err := instance.fingerprint(); err == nil {
+			inUse[fingerprint] = true
+		}
+	}
+
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	for key, cached := range clients {
+		if !inUse[key.fingerprint] {
+			cached.transport.CloseIdleConnections()
+			delete(clients, key)
+		}
+	}
+	for _, cache := range []*sync.Map{&controlPlaneIds, &availablePlugins} {
+		cache.Range(func(fingerprint, _ any) bool {
+			if !inUse[fingerprint.(string)] {
+				cache.Delete(fingerprint)
+			}
+			return true
+		})
+	}
+}
+
+func (i *Instance) newClient(ctx context.Context, fingerprint string, transport *http.Transport) (*kong.Client, error) {
+	headers := http.Header{}
+	headers.Set("User-Agent", "steadybit-extension-kong")
+	for name, value := range i.Headers {
+		headers.Set(name, value)
+	}
+	if i.UsesBasicAuth() {
+		credentials := base64.StdEncoding.EncodeToString([]byte(i.BasicAuth.Username + ":" + i.BasicAuth.Password))
+		headers.Set("Authorization", "Basic "+credentials)
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if len(i.HeaderKey) > 0 {
+		if len(i.HeaderValueFile) > 0 {
+			roundTripper = &secretHeaderTransport{key: i.HeaderKey, secret: &secretFile{path: i.HeaderValueFile}, next: transport}
+		} else if len(i.HeaderValue) > 0 {
+			headers.Set(i.HeaderKey, i.HeaderValue)
+		}
+	}
+
+	if i.IsKonnect() {
+		if len(i.Konnect.TokenFile) > 0 {
+			roundTripper = &secretHeaderTransport{key: "Authorization", prefix: "Bearer ", secret: &secretFile{path: i.Konnect.TokenFile}, next: roundTripper}
+		} else {
+			headers.Set("Authorization", "Bearer "+i.Konnect.Token)
+		}
+	}
+
+	roundTripper = &instrumentedTransport{instance: i.Name, next: roundTripper}
+	httpClient := kong.HTTPClientWithHeaders(&http.Client{Transport: roundTripper}, headers)
+	baseUrl := i.BaseUrl
+	if i.IsKonnect() {
+		var err error
+		baseUrl, err = i.Konnect.controlPlaneUrl(ctx, fingerprint, httpClient)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	client, err := kong.NewClient(&baseUrl, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	client.SetKonnectFlag(i.IsKonnect())
+	client.SetWorkspace(i.workspace)
+	return client, nil
+}
+
+// fingerprint identifies the configuration of the instance. It is hashed, as the configuration holds secrets which
+// must not end up in memory dumps or logs of the caches keyed by it. The TLS files count by their modification time and
+// size as well, so that certificates rotated in place lead to a new client.
+func (i *Instance) fingerprint() (string, error) {
+	b, err := json.Marshal(i)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	hash.Write(b)
+	for _, file := range []string{i.TLS.CaFile, i.TLS.ClientCertFile, i.TLS.ClientKeyFile} {
+		if len(file) == 0 {
+			continue
+		}
+		// unreadable files are reported once the client gets built
+		if info, err := os.Stat(file); err == nil {
+			_, _ = fmt.Fprintf(hash, "%s:%d:%d;", file, info.ModTime().UnixNano(), info.Size())
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// NewTransport returns a transport with the TLS settings for requests to an instance which don't go through its Kong
+// client, e.g., to its proxy.
+func (t *TLS) NewTransport() (*http.Transport, error) {
+	tlsConfig, err := t.clientConfig()
+	if err != nil {
+		return nil, err
+	}
+	return newTransport(tlsConfig), nil
+}
+
+func newTransport(tlsConfig *tls.Config) *http.Transport {
+	return &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   20,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// instrumentedTransport records the status and latency of every request to the admin API of an instance and traces it
+// as child of the action or discovery it was sent for.
+type instrumentedTransport struct {
+	instance string
+	next     http.RoundTripper
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := exttracing.Start(req.Context(), "kong admin API "+req.Method,
+		attribute.String("kong.instance.name", t.instance),
+		attribute.String("kong.entity.type", entityTypeOf(req.URL.Path)),
+		attribute.String("http.request.method", req.Method),
+		attribute.String("url.path", req.URL.Path),
+	)
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	status := 0
+	if err == nil {
+		status = resp.StatusCode
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, resp.Status)
+		}
+	}
+	extmetrics.ObserveAdminApiRequest(t.instance, status, time.Since(start))
+	exttracing.End(span, err)
+	return resp, err
+}
+
+// adminApiEntities are the kinds of entities, and other resources, the extension reads from and writes to the admin API.
+var adminApiEntities = []string{"services", "routes", "plugins", "consumers", "upstreams", "targets", "workspaces", "clustering", "config", "status", "metrics", "schemas", "timers"}
+
+// entityTypeOf tells the kind of entity a request to the admin API is about, i.e., the last collection named in its
+// path, e.g., routes for /services/{id}/routes. Collections and IDs alternate after the workspace or Konnect control
+// plane prefix, so an entity named like a collection isn't mistaken for one.
+func entityTypeOf(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	first := slices.IndexFunc(segments, func(segment string) bool {
+		return slices.Contains(adminApiEntities, segment)
+	})
+	if first < 0 {
+		return "root"
+	}
+	entityType := segments[first]
+	for i := first + 2; i < len(segments); i += 2 {
+		if slices.Contains(adminApiEntities, segments[i]) {
+			entityType = segments[i]
+		}
+	}
+	return entityType
+}
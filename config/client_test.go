@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetClientIsReusedForTheSameInstance(t *testing.T) {
+	instance := Instance{Name: "cached", BaseUrl: "http://localhost:8001"}
+
+	first, err := instance.GetClient(context.Background())
+	require.NoError(t, err)
+	second, err := instance.GetClient(context.Background())
+	require.NoError(t, err)
+
+	assert.Same(t, first, second)
+}
+
+func TestGetClientIsRebuiltWhenTheInstanceChanges(t *testing.T) {
+	instance := Instance{Name: "changing", BaseUrl: "http://localhost:8001"}
+	first, err := instance.GetClient(context.Background())
+	require.NoError(t, err)
+
+	instance.HeaderKey = "Kong-Admin-Token"
+	instance.HeaderValue = "rotated"
+	second, err := instance.GetClient(context.Background())
+	require.NoError(t, err)
+
+	assert.NotSame(t, first, second)
+	assert.Equal(t, "http://localhost:8001", second.BaseRootURL())
+}
+
+func TestFingerprintDoesNotContainSecrets(t *testing.T) {
+	instance := Instance{Name: "secret", BaseUrl: "http://localhost:8001", HeaderKey: "Kong-Admin-Token", HeaderValue: "rbac-token"}
+
+	fingerprint, err := instance.fingerprint()
+	require.NoError(t, err)
+
+	assert.NotContains(t, fingerprint, "rbac-token")
+	assert.Len(t, fingerprint, 64)
+}
+
+func TestClientSendsAllConfiguredHeadersAndBasicAuth(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"service","name":"service"}`))
+	}))
+	t.Cleanup(server.Close)
+	instance := Instance{
+		Name:        t.Name(),
+		BaseUrl:     server.URL,
+		HeaderKey:   "Kong-Admin-Token",
+		HeaderValue: "rbac-token",
+		Headers:     map[string]string{"X-Proxy-Auth": "proxy-secret"},
+		BasicAuth:   BasicAuth{Username: "admin", Password: "secret"},
+	}
+
+	_, err := instance.FindService(context.Background(), new("service"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "rbac-token", received.Get("Kong-Admin-Token"))
+	assert.Equal(t, "proxy-secret", received.Get("X-Proxy-Auth"))
+	assert.Equal(t, "Basic YWRtaW46c2VjcmV0", received.Get("Authorization"))
+	assert.Equal(t, []string{"Kong-Admin-Token", "X-Proxy-Auth"}, instance.HeaderNames())
+}
+
+func TestClientRecordsAdminApiRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"Not found"}`))
+	}))
+	t.Cleanup(server.Close)
+	instance := Instance{Name: t.Name(), BaseUrl: server.URL}
+
+	_, err := instance.FindService(context.Background(), new("unknown"))
+	require.Error(t, err)
+
+	metrics := httptest.NewRecorder()
+	promhttp.Handler().ServeHTTP(metrics, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, metrics.Body.String(), `kong_extension_admin_api_requests_total{instance="TestClientRecordsAdminApiRequests",status="404"} 1`)
+	assert.Contains(t, metrics.Body.String(), `kong_extension_admin_api_request_duration_seconds_count{instance="TestClientRecordsAdminApiRequests"} 1`)
+}
+
+func TestClientTracesAdminApiRequests(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"service","name":"service"}`))
+	}))
+	t.Cleanup(server.Close)
+	instance := Instance{Name: t.Name(), BaseUrl: server.URL}
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "prepare")
+	_, err := instance.FindService(ctx, new("service"))
+	parent.End()
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(t, "kong admin API GET", span.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	assert.Contains(t, span.Attributes(), attribute.String("kong.instance.name", t.Name()))
+	assert.Contains(t, span.Attributes(), attribute.String("kong.entity.type", "services"))
+	assert.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", 200))
+}
+
+func TestEntityTypeOf(t *testing.T) {
+	tests := map[string]string{
+		"/":                     "root",
+		"/services/products":    "services",
+		"/services/metrics":     "services",
+		"/services/s-1/routes":  "routes",
+		"/routes/r-1/plugins/p": "plugins",
+		"/upstreams/u-1/health": "upstreams",
+		"/team-a/services/s-1":  "services",
+		"/v2/control-planes/cp-1/core-entities/routes/r-1": "routes",
+	}
+	for path, expected := range tests {
+		assert.Equal(t, expected, entityTypeOf(path), path)
+	}
+}
+
+func TestValidateInstancesReportsConflictingHeaders(t *testing.T) {
+	err := validateInstances([]Instance{{
+		Name:        "conflicting",
+		BaseUrl:     "http://kong:8001",
+		HeaderKey:   "Kong-Admin-Token",
+		HeaderValue: "rbac-token",
+		Headers:     map[string]string{"kong-admin-token": "other", "Authorization": "Bearer token"},
+		BasicAuth:   BasicAuth{Username: "admin", Password: "secret"},
+	}})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "instance 'conflicting' configures the header 'kong-admin-token' more than once")
+	assert.Contains(t, err.Error(), "instance 'conflicting' configures basic auth and an Authorization header")
+}
@@ -5,8 +5,12 @@
 package config
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/rs/zerolog/log"
+	"time"
 )
 
 // Specification is the configuration specification for the extension. Configuration values can be applied
@@ -15,6 +19,28 @@ import (
 type Specification struct {
 	DiscoveryAttributesExcludesService []string `json:"discoveryAttributesExcludesService" split_words:"true" required:"false"`
 	DiscoveryAttributesExcludesRoute   []string `json:"discoveryAttributesExcludesRoute" split_words:"true" required:"false"`
+	// KongInstancesFile optionally points to a YAML or JSON file listing Kong instances in addition to the ones configured
+	// through STEADYBIT_EXTENSION_KONG_INSTANCE_<n>_* environment variables.
+	KongInstancesFile string `json:"kongInstancesFile" split_words:"true" required:"false"`
+	// KongInstancesFileReloadInterval defines how often the instances file is checked for changes. Zero disables reloading.
+	KongInstancesFileReloadInterval time.Duration `json:"kongInstancesFileReloadInterval" split_words:"true" required:"false" default:"10s"`
+	// AdminApiTimeout bounds every single request against a Kong admin API.
+	AdminApiTimeout time.Duration `json:"adminApiTimeout" split_words:"true" required:"false" default:"30s"`
+	// AdminApiRetry* configure how transient admin API failures, e.g., during rolling restarts of Kong, are retried.
+	AdminApiRetryMaxAttempts    int           `json:"adminApiRetryMaxAttempts" split_words:"true" required:"false" default:"3"`
+	AdminApiRetryInitialBackoff time.Duration `json:"adminApiRetryInitialBackoff" split_words:"true" required:"false" default:"250ms"`
+	AdminApiRetryMaxBackoff     time.Duration `json:"adminApiRetryMaxBackoff" split_words:"true" required:"false" default:"5s"`
+	AdminApiRetryStatusCodes    []int         `json:"adminApiRetryStatusCodes" split_words:"true" required:"false" default:"429,502,503,504"`
+	// ProbeInstancesOnStartup makes the extension refuse to start unless the admin API of every instance is reachable.
+	ProbeInstancesOnStartup bool `json:"probeInstancesOnStartup" split_words:"true" required:"false" default:"false"`
+	// Kubernetes* configure access to the Kubernetes API for instances managed by the Kong Ingress Controller. They
+	// default to the in-cluster configuration of the extension's service account.
+	KubernetesApiUrl    string `json:"kubernetesApiUrl" split_words:"true" required:"false"`
+	KubernetesTokenFile string `json:"kubernetesTokenFile" split_words:"true" required:"false" default:"/var/run/secrets/kubernetes.io/serviceaccount/token"`
+	KubernetesCaFile    string `json:"kubernetesCaFile" split_words:"true" required:"false" default:"/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"`
+	// EmergencyStopToken enables the emergency stop of all faults, which callers have to authenticate with as bearer
+	// token. The emergency stop isn't available without token.
+	EmergencyStopToken string `json:"-" split_words:"true" required:"false"`
 }
 
 var (
@@ -26,8 +52,48 @@ func ParseConfiguration() {
 	if err != nil {
 		log.Fatal().Err(err).Msgf("Failed to parse configuration from environment.")
 	}
+
+	err = LoadInstances()
+	if err != nil {
+		log.Fatal().Err(err).Msgf("Failed to load Kong instance configuration.")
+	}
 }
 
 func ValidateConfiguration() {
-	// You may optionally validate the configuration here.
+	if err := validateConfiguration(context.Background()); err != nil {
+		log.Fatal().Err(err).Msgf("Invalid configuration.")
+	}
+}
+
+func validateConfiguration(ctx context.Context) error {
+	instances := GetInstances()
+	if len(instances) == 0 && watchesInstancesFile() {
+		log.Warn().Msgf("No Kong instance is configured yet, waiting for instances to be added to %s.", Config.KongInstancesFile)
+		return nil
+	}
+	if len(instances) == 0 {
+		return errors.New("no Kong instance is configured, please configure at least one through STEADYBIT_EXTENSION_KONG_INSTANCE_<n>_* environment variables or STEADYBIT_EXTENSION_KONG_INSTANCES_FILE")
+	}
+	if err := validateInstances(instances); err != nil {
+		return err
+	}
+	for _, instance := range instances {
+		if instance.TLS.InsecureSkipVerify {
+			log.Warn().Msgf("TLS certificate verification is disabled for Kong instance %s", instance.Name)
+		}
+	}
+
+	if !Config.ProbeInstancesOnStartup {
+		return nil
+	}
+	var errs []error
+	for _, instance := range instances {
+		info, err := instance.Probe(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("instance '%s' at %s is not reachable: %w", instance.Name, instance.Origin(), err))
+			continue
+		}
+		log.Info().Msgf("Kong instance %s runs version %s with database '%s' (reachable: %t)", instance.Name, info.Version, info.Database, info.DatabaseReachable)
+	}
+	return errors.Join(errs...)
 }
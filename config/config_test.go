@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func withInstances(t *testing.T, instances ...Instance) {
+	previousConfig, previousInstances := Config, GetInstances()
+	SetInstances(instances)
+	t.Cleanup(func() {
+		Config = previousConfig
+		SetInstances(previousInstances)
+	})
+}
+
+func TestValidateConfigurationRequiresAnInstance(t *testing.T) {
+	withInstances(t)
+
+	err := validateConfiguration(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no Kong instance is configured")
+}
+
+func TestValidateConfigurationWaitsForInstancesOfTheInstancesFile(t *testing.T) {
+	withInstances(t)
+	Config.KongInstancesFile = "/etc/steadybit/kong-instances/instances.yaml"
+	Config.KongInstancesFileReloadInterval = time.Second
+
+	err := validateConfiguration(context.Background())
+
+	require.NoError(t, err)
+}
+
+func TestValidateConfigurationReportsAllMisconfiguredInstances(t *testing.T) {
+	withInstances(t,
+		Instance{BaseUrl: "http://kong:8001"},
+		Instance{Name: "gateway", BaseUrl: "/admin"},
+		Instance{Name: "gateway", BaseUrl: "http://kong:8001", HeaderKey: "Kong-Admin-Token"},
+		Instance{Name: "proxied", BaseUrl: "http://kong:8001", ProxyUrl: "kong:8000"},
+	)
+
+	err := validateConfiguration(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "instance #0 has no name")
+	assert.Contains(t, err.Error(), "instance 'gateway' has a malformed origin '/admin'")
+	assert.Contains(t, err.Error(), "instance name 'gateway' is configured more than once")
+	assert.Contains(t, err.Error(), "instance 'gateway' must configure the header key and value together")
+	assert.Contains(t, err.Error(), "instance 'proxied' has a malformed proxy URL 'kong:8000'")
+}
+
+func TestValidateConfigurationProbesInstances(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/status":
+			_, _ = w.Write([]byte(`{"database":{"reachable":true}}`))
+		case "/":
+			_, _ = w.Write([]byte(`{"version":"3.9.0","configuration":{"database":"off"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	withInstances(t, Instance{Name: "gateway", BaseUrl: server.URL})
+	Config.ProbeInstancesOnStartup = true
+
+	info, err := GetInstances()[0].Probe(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &InstanceInfo{Version: "3.9.0", Database: "off", DatabaseReachable: true, Edition: EditionCommunity}, info)
+
+	assert.NoError(t, validateConfiguration(context.Background()))
+}
+
+func TestValidateConfigurationFailsForUnreachableInstances(t *testing.T) {
+	withRetryPolicy(t)
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	withInstances(t, Instance{Name: "gateway", BaseUrl: server.URL})
+	Config.ProbeInstancesOnStartup = true
+
+	err := validateConfiguration(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "instance 'gateway' at "+server.URL+" is not reachable")
+}
@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ControlPlaneRole is the role Kong reports for control planes of a hybrid deployment.
+const ControlPlaneRole = "control_plane"
+
+// DataPlaneStaleAfter is the time after which a data plane which didn't contact its control plane is considered
+// disconnected. Data planes ping their control plane every 30 seconds, but the control plane keeps them listed for
+// much longer.
+const DataPlaneStaleAfter = 90 * time.Second
+
+// DataPlane is a data plane node of a hybrid deployment as reported by its control plane.
+type DataPlane struct {
+	Id         string            `json:"id"`
+	Hostname   string            `json:"hostname"`
+	Ip         string            `json:"ip"`
+	Version    string            `json:"version"`
+	SyncStatus string            `json:"sync_status"`
+	ConfigHash string            `json:"config_hash"`
+	LastSeen   int64             `json:"last_seen"`
+	Labels     map[string]string `json:"labels"`
+}
+
+func (d DataPlane) LastSeenAt() time.Time {
+	return time.Unix(d.LastSeen, 0)
+}
+
+// IsConnected tells whether the data plane contacted its control plane recently.
+func (d DataPlane) IsConnected(now time.Time) bool {
+	return now.Sub(d.LastSeenAt()) <= DataPlaneStaleAfter
+}
+
+// IsControlPlane tells whether the instance is the control plane of a hybrid deployment.
+func (i *Instance) IsControlPlane(ctx context.Context) (bool, error) {
+	if i.IsKonnect() {
+		return false, nil
+	}
+
+	root, err := i.getRoot(ctx)
+	if err != nil {
+		return false, err
+	}
+	if configuration, ok := root["configuration"].(map[string]any); ok {
+		role, _ := configuration["role"].(string)
+		return role == ControlPlaneRole, nil
+	}
+	return false, nil
+}
+
+// GetDataPlanes lists the data planes known to the control plane of a hybrid deployment, including disconnected ones.
+func (i *Instance) GetDataPlanes(ctx context.Context) ([]DataPlane, error) {
+	if i.IsKonnect() {
+		return nil, errors.New("listing the data planes of Konnect control planes isn't supported")
+	}
+	client, err := i.GetClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type query struct {
+		Size   int    `url:"size,omitempty"`
+		Offset string `url:"offset,omitempty"`
+	}
+	var dataPlanes []DataPlane
+	qs := query{Size: 1000}
+	for {
+		var page struct {
+			Data   []DataPlane `json:"data"`
+			Offset string      `json:"offset"`
+		}
+		_, err := call(ctx, true, func(ctx context.Context) (any, error) {
+			// data planes are listed cluster-wide, independent of workspaces
+			req, err := client.NewRequestRaw(http.MethodGet, client.BaseRootURL(), "/clustering/data-planes", qs, nil)
+			if err != nil {
+				return nil, err
+			}
+			return client.Do(ctx, req, &page)
+		})
+		if err != nil {
+			return nil, err
+		}
+		dataPlanes = append(dataPlanes, page.Data...)
+		if page.Offset == "" {
+			return dataPlanes, nil
+		}
+		qs.Offset = page.Offset
+	}
+}
+
+// GetConnectedDataPlanes lists the data planes which contacted the control plane recently.
+func (i *Instance) GetConnectedDataPlanes(ctx context.Context) ([]DataPlane, error) {
+	dataPlanes, err := i.GetDataPlanes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	connected := make([]DataPlane, 0, len(dataPlanes))
+	for _, dataPlane := range dataPlanes {
+		if dataPlane.IsConnected(now) {
+			connected = append(connected, dataPlane)
+		}
+	}
+	return connected, nil
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/kong/go-kong/kong"
+)
+
+// DbLessDatabase is the database Kong reports when running DB-less, i.e., configured declaratively through /config.
+const DbLessDatabase = "off"
+
+// DeclarativeConfig is the configuration of a DB-less instance together with the hash Kong reports for it.
+type DeclarativeConfig struct {
+	Content []byte
+	Hash    string
+}
+
+// IsDbLess tells whether the instance runs without database. The admin API of such instances is read-only,
+// changes have to be applied by replacing the whole declarative configuration.
+func (i *Instance) IsDbLess(ctx context.Context) (bool, error) {
+	if i.IsKonnect() {
+		return false, nil
+	}
+
+	root, err := i.getRoot(ctx)
+	if err != nil {
+		return false, err
+	}
+	return databaseOf(root) == DbLessDatabase, nil
+}
+
+// getRoot returns the node information of GET /, which includes the node's configuration.
+func (i *Instance) getRoot(ctx context.Context) (map[string]any, error) {
+	client, err := i.GetClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return call(ctx, true, func(ctx context.Context) (map[string]any, error) {
+		return client.Root(ctx)
+	})
+}
+
+func databaseOf(root map[string]any) string {
+	if configuration, ok := root["configuration"].(map[string]any); ok {
+		database, _ := configuration["database"].(string)
+		return database
+	}
+	return ""
+}
+
+// GetConfigurationHash returns the hash of the configuration currently applied by a DB-less instance.
+func (i *Instance) GetConfigurationHash(ctx context.Context) (string, error) {
+	client, err := i.GetClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	status, err := call(ctx, true, func(ctx context.Context) (*kong.Status, error) {
+		return client.Status(ctx)
+	})
+	if err != nil {
+		return "", err
+	}
+	return status.ConfigurationHash, nil
+}
+
+// GetDeclarativeConfig returns the configuration currently applied by a DB-less instance. The configuration is
+// only returned if it didn't change while being read, so that its content matches the hash.
+func (i *Instance) GetDeclarativeConfig(ctx context.Context) (*DeclarativeConfig, error) {
+	client, err := i.GetClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := i.GetConfigurationHash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	content, err := call(ctx, true, func(ctx context.Context) ([]byte, error) {
+		return client.Config(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	hashAfterwards, err := i.GetConfigurationHash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if hash != hashAfterwards {
+		return nil, errors.New("the declarative configuration changed while being read")
+	}
+	return &DeclarativeConfig{Content: content, Hash: hash}, nil
+}
+
+// ReloadDeclarativeConfig replaces the whole configuration of a DB-less instance. Replacing the configuration is
+// idempotent and therefore retried on transient errors.
+func (i *Instance) ReloadDeclarativeConfig(ctx context.Context, content []byte) error {
+	client, err := i.GetClient(ctx)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]string{"config": string(content)})
+	if err != nil {
+		return err
+	}
+	_, err = call(ctx, true, func(ctx context.Context) (any, error) {
+		return nil, client.ReloadDeclarativeRawConfig(ctx, bytes.NewReader(body), false, true)
+	})
+	return err
+}
@@ -5,34 +5,125 @@ package config
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/kong/go-kong/kong"
+	"github.com/rs/zerolog/log"
+	"io/fs"
+	"net/http"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 )
 
 type Instance struct {
-	Name        string `json:"name"`
-	BaseUrl     string `json:"baseUrl"`
-	HeaderKey   string `json:"headerKey"`
-	HeaderValue string `json:"headerValue"`
+	Name string `json:"name" yaml:"name"`
+	// Type defaults to InstanceTypeAdminApi.
+	Type        InstanceType `json:"type" yaml:"type"`
+	BaseUrl     string       `json:"baseUrl" yaml:"origin"`
+	HeaderKey   string       `json:"headerKey" yaml:"headerKey"`
+	HeaderValue string       `json:"headerValue" yaml:"headerValue"`
+	// HeaderValueFile optionally points to a file holding the header value, which is re-read whenever the file changes.
+	HeaderValueFile string `json:"headerValueFile" yaml:"headerValueFile"`
+	// Headers are sent with every request in addition to HeaderKey, e.g., a Kong RBAC token together with the
+	// credentials of an authenticating proxy in front of the admin API.
+	Headers   map[string]string `json:"headers" yaml:"headers"`
+	BasicAuth BasicAuth         `json:"basicAuth" yaml:"basicAuth"`
+	TLS       TLS               `json:"tls" yaml:"tls"`
+	// Workspaces lists the Kong Enterprise workspaces to discover, AllWorkspaces discovers all of them.
+	Workspaces []string `json:"workspaces" yaml:"workspaces"`
+	Konnect    Konnect  `json:"konnect" yaml:"konnect"`
+	// IngressController marks instances whose configuration is owned by the Kong Ingress Controller. Plugins are
+	// attached through KongPlugin resources instead of the admin API, which the controller would revert.
+	IngressController bool `json:"ingressController" yaml:"ingressController"`
+	// ProxyUrl optionally points to the proxy of Kong, which HTTP checks send their requests to.
+	ProxyUrl string `json:"proxyUrl" yaml:"proxyUrl"`
+
+	// workspace scopes all calls to a single workspace, see InWorkspace.
+	workspace string
+}
+
+// BasicAuth holds the credentials for admin APIs protected by HTTP basic authentication.
+type BasicAuth struct {
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
 }
 
 var (
-	Instances []Instance
+	instanceNameEnvPattern = regexp.MustCompile(`^STEADYBIT_EXTENSION_KONG_INSTANCE_(\d+)_NAME=`)
 )
 
-func init() {
-	name := getInstanceName(0)
-	for len(name) > 0 {
-		index := len(Instances)
-		Instances = append(Instances, Instance{
-			Name:        name,
-			BaseUrl:     getInstanceOrigin(index),
-			HeaderKey:   getAuthHeaderKey(index),
-			HeaderValue: getAuthHeaderValue(index),
+// LoadInstances collects the instances configured through environment variables and, if configured, the
+// instances file. Instances from environment variables come first.
+func LoadInstances() error {
+	instances, err := loadInstances()
+	if errors.Is(err, fs.ErrNotExist) && watchesInstancesFile() {
+		// the file may get written after the extension started, the watcher picks it up then
+		log.Warn().Err(err).Msg("Starting without the Kong instances of the instances file until it gets written.")
+		instances = getInstancesFromEnv()
+		err = validateInstances(instances)
+	}
+	if err != nil {
+		return err
+	}
+	SetInstances(instances)
+	return nil
+}
+
+func loadInstances() ([]Instance, error) {
+	instances := getInstancesFromEnv()
+	if len(Config.KongInstancesFile) > 0 {
+		fromFile, err := readInstancesFile(Config.KongInstancesFile)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, fromFile...)
+	}
+	if err := validateInstances(instances); err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// getInstancesFromEnv reads all numbered instances from the environment. Gaps in the numbering are
+// tolerated, instances are ordered by their number.
+func getInstancesFromEnv() []Instance {
+	var indices []int
+	for _, env := range os.Environ() {
+		if match := instanceNameEnvPattern.FindStringSubmatch(env); match != nil {
+			if index, err := strconv.Atoi(match[1]); err == nil {
+				indices = append(indices, index)
+			}
+		}
+	}
+	slices.Sort(indices)
+
+	instances := make([]Instance, 0, len(indices))
+	for _, index := range slices.Compact(indices) {
+		name := getInstanceName(index)
+		if len(name) == 0 {
+			continue
+		}
+		instances = append(instances, Instance{
+			Name:              name,
+			Type:              InstanceType(os.Getenv(fmt.Sprintf("STEADYBIT_EXTENSION_KONG_INSTANCE_%d_TYPE", index))),
+			BaseUrl:           getInstanceOrigin(index),
+			HeaderKey:         getAuthHeaderKey(index),
+			HeaderValue:       getAuthHeaderValue(index),
+			HeaderValueFile:   getAuthHeaderValueFile(index),
+			Headers:           getHeaders(index),
+			BasicAuth:         getBasicAuth(index),
+			TLS:               getTLS(index),
+			Workspaces:        getWorkspaces(index),
+			Konnect:           getKonnect(index),
+			IngressController: getIngressController(index),
+			ProxyUrl:          getProxyUrl(index),
 		})
-		name = getInstanceName(len(Instances))
 	}
+	return instances
 }
 
 func getInstanceName(n int) string {
@@ -51,8 +142,147 @@ func getAuthHeaderValue(n int) string {
 	return os.Getenv(fmt.Sprintf("STEADYBIT_EXTENSION_KONG_INSTANCE_%d_HEADER_VALUE", n))
 }
 
+func getAuthHeaderValueFile(n int) string {
+	return os.Getenv(fmt.Sprintf("STEADYBIT_EXTENSION_KONG_INSTANCE_%d_HEADER_VALUE_FILE", n))
+}
+
+// getHeaders parses a comma-separated list of name=value pairs.
+func getHeaders(n int) map[string]string {
+	value := os.Getenv(fmt.Sprintf("STEADYBIT_EXTENSION_KONG_INSTANCE_%d_HEADERS", n))
+	if len(value) == 0 {
+		return nil
+	}
+	headers := map[string]string{}
+	for pair := range strings.SplitSeq(value, ",") {
+		name, value, _ := strings.Cut(pair, "=")
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return headers
+}
+
+func getBasicAuth(n int) BasicAuth {
+	return BasicAuth{
+		Username: os.Getenv(fmt.Sprintf("STEADYBIT_EXTENSION_KONG_INSTANCE_%d_BASIC_AUTH_USERNAME", n)),
+		Password: os.Getenv(fmt.Sprintf("STEADYBIT_EXTENSION_KONG_INSTANCE_%d_BASIC_AUTH_PASSWORD", n)),
+	}
+}
+
+func getWorkspaces(n int) []string {
+	value := os.Getenv(fmt.Sprintf("STEADYBIT_EXTENSION_KONG_INSTANCE_%d_WORKSPACES", n))
+	if len(value) == 0 {
+		return nil
+	}
+	var workspaces []string
+	for workspace := range strings.SplitSeq(value, ",") {
+		if workspace = strings.TrimSpace(workspace); len(workspace) > 0 {
+			workspaces = append(workspaces, workspace)
+		}
+	}
+	return workspaces
+}
+
+func getKonnect(n int) Konnect {
+	return Konnect{
+		Region:           os.Getenv(fmt.Sprintf("STEADYBIT_EXTENSION_KONG_INSTANCE_%d_KONNECT_REGION", n)),
+		ControlPlaneId:   os.Getenv(fmt.Sprintf("STEADYBIT_EXTENSION_KONG_INSTANCE_%d_KONNECT_CONTROL_PLANE_ID", n)),
+		ControlPlaneName: os.Getenv(fmt.Sprintf("STEADYBIT_EXTENSION_KONG_INSTANCE_%d_KONNECT_CONTROL_PLANE_NAME", n)),
+		Token:            os.Getenv(fmt.Sprintf("STEADYBIT_EXTENSION_KONG_INSTANCE_%d_KONNECT_TOKEN", n)),
+		TokenFile:        os.Getenv(fmt.Sprintf("STEADYBIT_EXTENSION_KONG_INSTANCE_%d_KONNECT_TOKEN_FILE", n)),
+		ApiUrl:           os.Getenv(fmt.Sprintf("STEADYBIT_EXTENSION_KONG_INSTANCE_%d_KONNECT_API_URL", n)),
+	}
+}
+
+func getIngressController(n int) bool {
+	ingressController, _ := strconv.ParseBool(os.Getenv(fmt.Sprintf("STEADYBIT_EXTENSION_KONG_INSTANCE_%d_INGRESS_CONTROLLER", n)))
+	return ingressController
+}
+
+func getProxyUrl(n int) string {
+	return os.Getenv(fmt.Sprintf("STEADYBIT_EXTENSION_KONG_INSTANCE_%d_PROXY_URL", n))
+}
+
+func getTLS(n int) TLS {
+	insecureSkipVerify, _ := strconv.ParseBool(os.Getenv(fmt.Sprintf("STEADYBIT_EXTENSION_KONG_INSTANCE_%d_TLS_INSECURE_SKIP_VERIFY", n)))
+	return TLS{
+		CaFile:             os.Getenv(fmt.Sprintf("STEADYBIT_EXTENSION_KONG_INSTANCE_%d_TLS_CA_FILE", n)),
+		ClientCertFile:     os.Getenv(fmt.Sprintf("STEADYBIT_EXTENSION_KONG_INSTANCE_%d_TLS_CLIENT_CERT_FILE", n)),
+		ClientKeyFile:      os.Getenv(fmt.Sprintf("STEADYBIT_EXTENSION_KONG_INSTANCE_%d_TLS_CLIENT_KEY_FILE", n)),
+		InsecureSkipVerify: insecureSkipVerify,
+	}
+}
+
+// validateInstances reports all misconfigured instances at once: missing or duplicate names, malformed origins,
+// incomplete headers and unusable TLS settings.
+func validateInstances(instances []Instance) error {
+	var errs []error
+	seen := make(map[string]bool, len(instances))
+	for index, instance := range instances {
+		if len(instance.Name) == 0 {
+			errs = append(errs, fmt.Errorf("instance #%d has no name", index))
+		} else if seen[instance.Name] {
+			errs = append(errs, fmt.Errorf("instance name '%s' is configured more than once", instance.Name))
+		}
+		seen[instance.Name] = true
+
+		switch instance.Type {
+		case "", InstanceTypeAdminApi:
+			if !isHttpUrl(instance.BaseUrl) {
+				errs = append(errs, fmt.Errorf("instance '%s' has a malformed origin '%s', expected an absolute http(s) URL", instance.Name, instance.BaseUrl))
+			}
+		case InstanceTypeKonnect:
+			errs = append(errs, instance.Konnect.validate(&instance)...)
+		default:
+			errs = append(errs, fmt.Errorf("instance '%s' has the unknown type '%s', expected '%s' or '%s'", instance.Name, instance.Type, InstanceTypeAdminApi, InstanceTypeKonnect))
+		}
+
+		hasHeaderValue := len(instance.HeaderValue) > 0 || len(instance.HeaderValueFile) > 0
+		if (len(instance.HeaderKey) == 0) == hasHeaderValue {
+			errs = append(errs, fmt.Errorf("instance '%s' must configure the header key and value together", instance.Name))
+		}
+		if len(instance.HeaderValue) > 0 && len(instance.HeaderValueFile) > 0 {
+			errs = append(errs, fmt.Errorf("instance '%s' must configure either a header value or a header value file", instance.Name))
+		}
+		if len(instance.HeaderValueFile) > 0 {
+			if _, err := os.ReadFile(instance.HeaderValueFile); err != nil {
+				errs = append(errs, fmt.Errorf("instance '%s' has an unreadable header value file: %w", instance.Name, err))
+			}
+		}
+
+		for name, value := range instance.Headers {
+			if len(name) == 0 || len(value) == 0 {
+				errs = append(errs, fmt.Errorf("instance '%s' has a header without name or value", instance.Name))
+			} else if len(instance.HeaderKey) > 0 && http.CanonicalHeaderKey(name) == http.CanonicalHeaderKey(instance.HeaderKey) {
+				errs = append(errs, fmt.Errorf("instance '%s' configures the header '%s' more than once", instance.Name, name))
+			} else if len(instance.BasicAuth.Username) > 0 && http.CanonicalHeaderKey(name) == "Authorization" {
+				errs = append(errs, fmt.Errorf("instance '%s' configures basic auth and an Authorization header", instance.Name))
+			}
+		}
+		if len(instance.BasicAuth.Username) == 0 && len(instance.BasicAuth.Password) > 0 {
+			errs = append(errs, fmt.Errorf("instance '%s' configures a basic auth password without username", instance.Name))
+		}
+
+		if len(instance.ProxyUrl) > 0 && !isHttpUrl(instance.ProxyUrl) {
+			errs = append(errs, fmt.Errorf("instance '%s' has a malformed proxy URL '%s', expected an absolute http(s) URL", instance.Name, instance.ProxyUrl))
+		}
+
+		if slices.Contains(instance.Workspaces, "") {
+			errs = append(errs, fmt.Errorf("instance '%s' lists an empty workspace name", instance.Name))
+		}
+
+		if _, err := instance.TLS.clientConfig(); err != nil {
+			errs = append(errs, fmt.Errorf("instance '%s' has invalid TLS settings: %w", instance.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func isHttpUrl(value string) bool {
+	parsed, err := url.ParseRequestURI(value)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
 func FindInstanceByName(name string) (*Instance, error) {
-	for _, i := range Instances {
+	for _, i := range GetInstances() {
 		if i.Name == name {
 			return &i, nil
 		}
@@ -61,40 +291,55 @@ func FindInstanceByName(name string) (*Instance, error) {
 }
 
 func (i *Instance) IsAuthenticated() bool {
-	return len(i.HeaderKey) > 0 && len(i.HeaderValue) > 0
+	return len(i.HeaderNames()) > 0 || i.UsesBasicAuth() || i.IsKonnect()
 }
 
-func (i *Instance) GetClient() (*kong.Client, error) {
-	headers := map[string][]string{
-		"User-Agent": {"steadybit-extension-kong"},
+// HeaderNames returns the sorted names of all headers sent to the admin API, without revealing their values.
+func (i *Instance) HeaderNames() []string {
+	names := make([]string, 0, len(i.Headers)+1)
+	if len(i.HeaderKey) > 0 && (len(i.HeaderValue) > 0 || len(i.HeaderValueFile) > 0) {
+		names = append(names, i.HeaderKey)
 	}
-
-	if i.IsAuthenticated() {
-		headers[i.HeaderKey] = []string{i.HeaderValue}
+	for name := range i.Headers {
+		names = append(names, name)
 	}
+	slices.Sort(names)
+	return names
+}
 
-	client := kong.HTTPClientWithHeaders(nil, headers)
-	return kong.NewClient(&i.BaseUrl, client)
+func (i *Instance) UsesBasicAuth() bool {
+	return len(i.BasicAuth.Username) > 0
+}
+
+// withTimeout bounds a single admin API request by the configured timeout while still honoring the caller's context.
+func withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if Config.AdminApiTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, Config.AdminApiTimeout)
 }
 
-func (i *Instance) FindService(nameOrId *string) (*kong.Service, error) {
-	client, err := i.GetClient()
+func (i *Instance) FindService(ctx context.Context, nameOrId *string) (*kong.Service, error) {
+	client, err := i.GetClient(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx := context.Background()
-	return client.Services.Get(ctx, nameOrId)
+	return call(ctx, true, func(ctx context.Context) (*kong.Service, error) {
+		return client.Services.Get(ctx, nameOrId)
+	})
 }
 
-func (i *Instance) FindRoute(service *kong.Service, nameOrId *string) (*kong.Route, error) {
-	client, err := i.GetClient()
+// ErrRouteNotFound is returned by FindRoute if the service has no such route, e.g., because it got deleted.
+var ErrRouteNotFound = errors.New("route not found")
+
+func (i *Instance) FindRoute(ctx context.Context, service *kong.Service, nameOrId *string) (*kong.Route, error) {
+	client, err := i.GetClient(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx := context.Background()
-	routes, _, err := client.Routes.ListForService(ctx, service.ID, nil)
+	routes, _, err := listRoutesForService(ctx, client, service.ID, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -107,91 +352,174 @@ func (i *Instance) FindRoute(service *kong.Service, nameOrId *string) (*kong.Rou
 		}
 	}
 	if routeFound == nil {
-		return nil, fmt.Errorf("the route %s does not belong to the service %s", *nameOrId, kong.StringValue(service.Name))
+		return nil, fmt.Errorf("%w: the route %s does not belong to the service %s", ErrRouteNotFound, *nameOrId, kong.StringValue(service.Name))
 	}
 	return routeFound, nil
 }
 
-func (i *Instance) FindConsumer(nameOrId *string) (*kong.Consumer, error) {
-	client, err := i.GetClient()
+func (i *Instance) FindConsumer(ctx context.Context, nameOrId *string) (*kong.Consumer, error) {
+	client, err := i.GetClient(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx := context.Background()
-	return client.Consumers.Get(ctx, nameOrId)
+	return call(ctx, true, func(ctx context.Context) (*kong.Consumer, error) {
+		return client.Consumers.Get(ctx, nameOrId)
+	})
 }
 
-func (i *Instance) CreatePluginAtAnyLevel(plugin *kong.Plugin) (*kong.Plugin, error) {
-	client, err := i.GetClient()
+// CreatePluginAtAnyLevel creates the plugin for its route, or its service if no route is set. Plugins with
+// a preset ID are created through an idempotent upsert, which allows to safely retry their creation.
+func (i *Instance) CreatePluginAtAnyLevel(ctx context.Context, plugin *kong.Plugin) (*kong.Plugin, error) {
+	client, err := i.GetClient(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx := context.Background()
-	if plugin.Route != nil {
-		return client.Plugins.CreateForRoute(ctx, plugin.Route.ID, plugin)
-	}
-
-	return client.Plugins.CreateForService(ctx, plugin.Service.ID, plugin)
+	return call(ctx, plugin.ID != nil, func(ctx context.Context) (*kong.Plugin, error) {
+		if plugin.Route != nil {
+			return client.Plugins.CreateForRoute(ctx, plugin.Route.ID, plugin)
+		}
+		return client.Plugins.CreateForService(ctx, plugin.Service.ID, plugin)
+	})
 }
 
-func (i *Instance) UpdatePluginForService(serviceId *string, plugin *kong.Plugin) (*kong.Plugin, error) {
-	client, err := i.GetClient()
+func (i *Instance) UpdatePluginForService(ctx context.Context, serviceId *string, plugin *kong.Plugin) (*kong.Plugin, error) {
+	client, err := i.GetClient(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx := context.Background()
-	return client.Plugins.UpdateForService(ctx, serviceId, plugin)
+	return call(ctx, true, func(ctx context.Context) (*kong.Plugin, error) {
+		return client.Plugins.UpdateForService(ctx, serviceId, plugin)
+	})
 }
 
-func (i *Instance) UpdatePluginForRoute(routeId *string, plugin *kong.Plugin) (*kong.Plugin, error) {
-	client, err := i.GetClient()
+func (i *Instance) UpdatePluginForRoute(ctx context.Context, routeId *string, plugin *kong.Plugin) (*kong.Plugin, error) {
+	client, err := i.GetClient(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx := context.Background()
-	return client.Plugins.UpdateForRoute(ctx, routeId, plugin)
+	return call(ctx, true, func(ctx context.Context) (*kong.Plugin, error) {
+		return client.Plugins.UpdateForRoute(ctx, routeId, plugin)
+	})
 }
 
-func (i *Instance) DeletePluginForService(serviceId *string, nameOrID *string) error {
-	client, err := i.GetClient()
+func (i *Instance) DeletePluginForService(ctx context.Context, serviceId *string, nameOrID *string) error {
+	client, err := i.GetClient(ctx)
 	if err != nil {
 		return err
 	}
 
-	ctx := context.Background()
-	return client.Plugins.DeleteForService(ctx, serviceId, nameOrID)
+	_, err = call(ctx, true, func(ctx context.Context) (any, error) {
+		return nil, client.Plugins.DeleteForService(ctx, serviceId, nameOrID)
+	})
+	return err
 }
 
-func (i *Instance) DeletePluginForRoute(routeId *string, nameOrID *string) error {
-	client, err := i.GetClient()
+func (i *Instance) DeletePluginForRoute(ctx context.Context, routeId *string, nameOrID *string) error {
+	client, err := i.GetClient(ctx)
 	if err != nil {
 		return err
 	}
 
-	ctx := context.Background()
-	return client.Plugins.DeleteForRoute(ctx, routeId, nameOrID)
+	_, err = call(ctx, true, func(ctx context.Context) (any, error) {
+		return nil, client.Plugins.DeleteForRoute(ctx, routeId, nameOrID)
+	})
+	return err
 }
 
-func (i *Instance) GetServices() ([]*kong.Service, error) {
-	client, err := i.GetClient()
+func (i *Instance) GetServices(ctx context.Context) ([]*kong.Service, error) {
+	client, err := i.GetClient(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx := context.Background()
-	return client.Services.ListAll(ctx)
+	var services []*kong.Service
+	opt := &kong.ListOpt{Size: 1000}
+	for opt != nil {
+		var page []*kong.Service
+		page, opt, err = listServices(ctx, client, opt)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, page...)
+	}
+	return services, nil
+}
+
+func listServices(ctx context.Context, client *kong.Client, opt *kong.ListOpt) ([]*kong.Service, *kong.ListOpt, error) {
+	var next *kong.ListOpt
+	services, err := call(ctx, true, func(ctx context.Context) ([]*kong.Service, error) {
+		var services []*kong.Service
+		var err error
+		services, next, err = client.Services.List(ctx, opt)
+		return services, err
+	})
+	return services, next, err
 }
 
-func (i *Instance) GetRoutesForService(serviceNameOrID *string) ([]*kong.Route, *kong.ListOpt, error) {
-	client, err := i.GetClient()
+func (i *Instance) GetRoutesForService(ctx context.Context, serviceNameOrID *string) ([]*kong.Route, *kong.ListOpt, error) {
+	client, err := i.GetClient(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	ctx := context.Background()
-	return client.Routes.ListForService(ctx, serviceNameOrID, nil)
+	return listRoutesForService(ctx, client, serviceNameOrID, nil)
+}
+
+func (i *Instance) GetAllRoutesForService(ctx context.Context, serviceNameOrID *string) ([]*kong.Route, error) {
+	client, err := i.GetClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var routes []*kong.Route
+	opt := &kong.ListOpt{Size: 1000}
+	for opt != nil {
+		var page []*kong.Route
+		page, opt, err = listRoutesForService(ctx, client, serviceNameOrID, opt)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, page...)
+	}
+	return routes, nil
+}
+
+func listRoutesForService(ctx context.Context, client *kong.Client, serviceNameOrID *string, opt *kong.ListOpt) ([]*kong.Route, *kong.ListOpt, error) {
+	var next *kong.ListOpt
+	routes, err := call(ctx, true, func(ctx context.Context) ([]*kong.Route, error) {
+		var routes []*kong.Route
+		var err error
+		routes, next, err = client.Routes.ListForService(ctx, serviceNameOrID, opt)
+		return routes, err
+	})
+	return routes, next, err
+}
+
+// GetPluginsForService returns the plugins scoped to the service, including those scoped to the service and one of its
+// routes at once. Plugins scoped to a route only aren't included, see GetPluginsForRoute.
+func (i *Instance) GetPluginsForService(ctx context.Context, serviceNameOrID *string) ([]*kong.Plugin, error) {
+	client, err := i.GetClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return call(ctx, true, func(ctx context.Context) ([]*kong.Plugin, error) {
+		return client.Plugins.ListAllForService(ctx, serviceNameOrID)
+	})
+}
+
+// GetPluginsForRoute returns the plugins configured for the route.
+func (i *Instance) GetPluginsForRoute(ctx context.Context, routeID *string) ([]*kong.Plugin, error) {
+	client, err := i.GetClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return call(ctx, true, func(ctx context.Context) ([]*kong.Plugin, error) {
+		return client.Plugins.ListAllForRoute(ctx, routeID)
+	})
 }
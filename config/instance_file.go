@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+)
+
+// instancesFile is the format of the optional instances file. JSON files are supported as well, as JSON is a subset of YAML.
+//
+//	instances:
+//	  - name: gateway-eu
+//	    origin: https://kong-eu.example.com:8001
+//	    headerKey: Kong-Admin-Token
+//	    headerValue: secret
+//	    tls:
+//	      caFile: /etc/ssl/kong-ca.crt
+type instancesFile struct {
+	Instances []Instance `yaml:"instances"`
+}
+
+func readInstancesFile(path string) ([]Instance, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read instances file '%s': %w", path, err)
+	}
+	return parseInstancesFile(path, content)
+}
+
+func parseInstancesFile(path string, content []byte) ([]Instance, error) {
+	var file instancesFile
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse instances file '%s': %w", path, err)
+	}
+	for i, instance := range file.Instances {
+		if len(instance.Name) == 0 {
+			return nil, fmt.Errorf("instance #%d in instances file '%s' has no name", i+1, path)
+		}
+	}
+	return file.Instances, nil
+}
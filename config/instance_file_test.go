@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseInstancesFileFromYaml(t *testing.T) {
+	instances, err := parseInstancesFile("instances.yaml", []byte(`
+instances:
+  - name: gateway-eu
+    origin: https://kong-eu.example.com:8001
+    headerKey: Kong-Admin-Token
+    headerValue: secret
+  - name: gateway-us
+    origin: http://kong-us.example.com:8001
+`))
+
+	require.NoError(t, err)
+	assert.Equal(t, []Instance{
+		{Name: "gateway-eu", BaseUrl: "https://kong-eu.example.com:8001", HeaderKey: "Kong-Admin-Token", HeaderValue: "secret"},
+		{Name: "gateway-us", BaseUrl: "http://kong-us.example.com:8001"},
+	}, instances)
+}
+
+func TestParseInstancesFileFromJson(t *testing.T) {
+	instances, err := parseInstancesFile("instances.json", []byte(`{"instances": [{"name": "gateway", "origin": "http://kong:8001"}]}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, []Instance{{Name: "gateway", BaseUrl: "http://kong:8001"}}, instances)
+}
+
+func TestParseInstancesFileRejectsUnknownFields(t *testing.T) {
+	_, err := parseInstancesFile("instances.yaml", []byte(`
+instances:
+  - name: gateway
+    orign: http://kong:8001
+`))
+
+	assert.ErrorContains(t, err, "failed to parse instances file")
+}
+
+func TestLoadInstancesMergesEnvironmentAndFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "instances.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("instances:\n  - name: from-file\n    origin: http://file:8001\n"), 0o600))
+	t.Setenv("STEADYBIT_EXTENSION_KONG_INSTANCE_0_NAME", "from-env-0")
+	t.Setenv("STEADYBIT_EXTENSION_KONG_INSTANCE_0_ORIGIN", "http://env-0:8001")
+	t.Setenv("STEADYBIT_EXTENSION_KONG_INSTANCE_2_NAME", "from-env-2")
+	t.Setenv("STEADYBIT_EXTENSION_KONG_INSTANCE_2_ORIGIN", "http://env-2:8001")
+	withInstancesFile(t, path)
+
+	err := LoadInstances()
+
+	require.NoError(t, err)
+	names := make([]string, 0, len(GetInstances()))
+	for _, instance := range GetInstances() {
+		names = append(names, instance.Name)
+	}
+	assert.Equal(t, []string{"from-env-0", "from-env-2", "from-file"}, names)
+}
+
+func TestLoadInstancesReportsDuplicatesAndMalformedOrigins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "instances.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+instances:
+  - name: gateway
+    origin: http://kong:8001
+  - name: gateway
+    origin: http://kong-2:8001
+  - name: broken
+    origin: kong:8001
+`), 0o600))
+	withInstancesFile(t, path)
+
+	err := LoadInstances()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "instance name 'gateway' is configured more than once")
+	assert.Contains(t, err.Error(), "instance 'broken' has a malformed origin 'kong:8001'")
+}
+
+func withInstancesFile(t *testing.T, path string) {
+	previousConfig, previousInstances := Config, GetInstances()
+	Config.KongInstancesFile = path
+	t.Cleanup(func() {
+		Config = previousConfig
+		SetInstances(previousInstances)
+	})
+}
+
+func TestGetInstancesFromEnvParsesHeadersAndBasicAuth(t *testing.T) {
+	t.Setenv("STEADYBIT_EXTENSION_KONG_INSTANCE_0_NAME", "gateway")
+	t.Setenv("STEADYBIT_EXTENSION_KONG_INSTANCE_0_ORIGIN", "http://kong:8001")
+	t.Setenv("STEADYBIT_EXTENSION_KONG_INSTANCE_0_HEADERS", "Kong-Admin-Token=rbac-token, X-Proxy-Auth=a=b")
+	t.Setenv("STEADYBIT_EXTENSION_KONG_INSTANCE_0_BASIC_AUTH_USERNAME", "admin")
+	t.Setenv("STEADYBIT_EXTENSION_KONG_INSTANCE_0_BASIC_AUTH_PASSWORD", "secret")
+
+	instances := getInstancesFromEnv()
+
+	require.Len(t, instances, 1)
+	assert.Equal(t, map[string]string{"Kong-Admin-Token": "rbac-token", "X-Proxy-Auth": "a=b"}, instances[0].Headers)
+	assert.Equal(t, BasicAuth{Username: "admin", Password: "secret"}, instances[0].BasicAuth)
+}
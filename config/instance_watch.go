@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	instances atomic.Pointer[[]Instance]
+
+	subscribersMu sync.Mutex
+	subscribers   []chan struct{}
+
+	// pinned holds the instance every running execution has been prepared with, keyed by execution ID.
+	pinned sync.Map
+)
+
+// GetInstances returns the currently configured instances. The returned slice must not be modified.
+func GetInstances() []Instance {
+	if current := instances.Load(); current != nil {
+		return *current
+	}
+	return nil
+}
+
+// SetInstances atomically replaces the configured instances and notifies all subscribers.
+func SetInstances(updated []Instance) {
+	instances.Store(&updated)
+	pruneClients()
+
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+	for _, ch := range subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// a notification is already pending
+		}
+	}
+}
+
+// SubscribeInstanceChanges returns a channel receiving a notification whenever the configured instances change.
+func SubscribeInstanceChanges() <-chan struct{} {
+	ch := make(chan struct{}, 1)
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+	subscribers = append(subscribers, ch)
+	return ch
+}
+
+// WatchInstancesFile periodically re-reads the instances file, if configured, and swaps the configured
+// instances as soon as the file changes. This picks up updates of mounted Kubernetes secrets, too. An
+// invalid file is logged and keeps the previous instances in place.
+func WatchInstancesFile(ctx context.Context) {
+	if !watchesInstancesFile() {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(Config.KongInstancesFileReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := reloadInstances(); err != nil {
+					log.Error().Err(err).Msgf("Failed to reload Kong instance configuration from %s, keeping the previous configuration.", Config.KongInstancesFile)
+				}
+			}
+		}
+	}()
+}
+
+// watchesInstancesFile tells whether an instances file is configured and reloaded, which may fill in instances after
+// the extension started.
+func watchesInstancesFile() bool {
+	return len(Config.KongInstancesFile) > 0 && Config.KongInstancesFileReloadInterval > 0
+}
+
+// reloadInstances loads the instances again and applies them if they differ from the current ones.
+func reloadInstances() error {
+	loaded, err := loadInstances()
+	if err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(loaded, GetInstances()) {
+		return nil
+	}
+
+	names := make([]string, 0, len(loaded))
+	for _, instance := range loaded {
+		names = append(names, instance.Name)
+	}
+	log.Info().Msgf("Reloaded Kong instance configuration: %v", names)
+	SetInstances(loaded)
+	return nil
+}
+
+// PinInstance binds an execution to the instance it has been prepared with, so that a reload of the
+// configuration doesn't affect the execution until it is unpinned.
+func PinInstance(executionId uuid.UUID, instance Instance) {
+	pinned.Store(executionId, instance)
+}
+
+// UnpinInstance releases the instance an execution has been bound to.
+func UnpinInstance(executionId uuid.UUID) {
+	if _, ok := pinned.LoadAndDelete(executionId); ok {
+		pruneClients()
+	}
+}
+
+// FindInstanceForExecution returns the instance the execution has been pinned to. Executions which are unknown,
+// e.g., because the extension got restarted in the meantime, fall back to the configured instance of that name.
+func FindInstanceForExecution(executionId uuid.UUID, name string) (*Instance, error) {
+	if value, ok := pinned.Load(executionId); ok {
+		instance := value.(Instance)
+		if instance.Name != name {
+			return nil, fmt.Errorf("execution is bound to instance '%s'", instance.Name)
+		}
+		return &instance, nil
+	}
+	return FindInstanceByName(name)
+}
+
+// instancesInUse returns all instances which are either configured or pinned by a running execution.
+func instancesInUse() []Instance {
+	inUse := slices.Clone(GetInstances())
+	pinned.Range(func(_, value any) bool {
+		inUse = append(inUse, value.(Instance))
+		return true
+	})
+	return inUse
+}
@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReloadInstancesSwapsChangedInstancesAndNotifies(t *testing.T) {
+	path := writeInstancesFile(t, "instances:\n  - name: gateway\n    origin: http://kong:8001\n    headerKey: Kong-Admin-Token\n    headerValue: initial\n")
+	withInstancesFile(t, path)
+	require.NoError(t, LoadInstances())
+	changes := SubscribeInstanceChanges()
+
+	writeInstancesFileTo(t, path, "instances:\n  - name: gateway\n    origin: http://kong:8001\n    headerKey: Kong-Admin-Token\n    headerValue: rotated\n")
+	err := reloadInstances()
+
+	require.NoError(t, err)
+	instance, err := FindInstanceByName("gateway")
+	require.NoError(t, err)
+	assert.Equal(t, "rotated", instance.HeaderValue)
+	assert.Len(t, changes, 1)
+}
+
+func TestReloadInstancesIgnoresUnchangedFile(t *testing.T) {
+	path := writeInstancesFile(t, "instances:\n  - name: gateway\n    origin: http://kong:8001\n")
+	withInstancesFile(t, path)
+	require.NoError(t, LoadInstances())
+	changes := SubscribeInstanceChanges()
+
+	err := reloadInstances()
+
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestReloadInstancesKeepsPreviousInstancesWhenFileIsInvalid(t *testing.T) {
+	path := writeInstancesFile(t, "instances:\n  - name: gateway\n    origin: http://kong:8001\n")
+	withInstancesFile(t, path)
+	require.NoError(t, LoadInstances())
+
+	writeInstancesFileTo(t, path, "instances:\n  - name: gateway\n    origin: kong:8001\n")
+	err := reloadInstances()
+
+	require.Error(t, err)
+	instance, err := FindInstanceByName("gateway")
+	require.NoError(t, err)
+	assert.Equal(t, "http://kong:8001", instance.BaseUrl)
+}
+
+func TestWatchInstancesFilePicksUpChanges(t *testing.T) {
+	path := writeInstancesFile(t, "instances:\n  - name: gateway\n    origin: http://kong:8001\n")
+	withInstancesFile(t, path)
+	Config.KongInstancesFileReloadInterval = 10 * time.Millisecond
+	require.NoError(t, LoadInstances())
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	WatchInstancesFile(ctx)
+	writeInstancesFileTo(t, path, "instances:\n  - name: gateway\n    origin: http://kong:8001\n  - name: added\n    origin: http://kong-2:8001\n")
+
+	assert.Eventually(t, func() bool {
+		_, err := FindInstanceByName("added")
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestWatchInstancesFilePicksUpFileWrittenAfterStart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "instances.yaml")
+	withInstancesFile(t, path)
+	Config.KongInstancesFileReloadInterval = 10 * time.Millisecond
+	require.NoError(t, LoadInstances())
+	assert.Empty(t, GetInstances())
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	WatchInstancesFile(ctx)
+	writeInstancesFileTo(t, path, "instances:\n  - name: gateway\n    origin: http://kong:8001\n")
+
+	assert.Eventually(t, func() bool {
+		_, err := FindInstanceByName("gateway")
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestPinnedInstanceSurvivesReload(t *testing.T) {
+	executionId := uuid.New()
+	previous := GetInstances()
+	t.Cleanup(func() { SetInstances(previous) })
+	SetInstances([]Instance{{Name: "gateway", BaseUrl: "http://kong:8001", HeaderKey: "Kong-Admin-Token", HeaderValue: "initial"}})
+	PinInstance(executionId, GetInstances()[0])
+
+	SetInstances([]Instance{{Name: "gateway", BaseUrl: "http://kong:8001", HeaderKey: "Kong-Admin-Token", HeaderValue: "rotated"}})
+
+	instance, err := FindInstanceForExecution(executionId, "gateway")
+	require.NoError(t, err)
+	assert.Equal(t, "initial", instance.HeaderValue)
+
+	UnpinInstance(executionId)
+
+	instance, err = FindInstanceForExecution(executionId, "gateway")
+	require.NoError(t, err)
+	assert.Equal(t, "rotated", instance.HeaderValue)
+}
+
+func writeInstancesFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "instances.yaml")
+	writeInstancesFileTo(t, path, content)
+	return path
+}
+
+func writeInstancesFileTo(t *testing.T, path string, content string) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type InstanceType string
+
+const (
+	// InstanceTypeAdminApi addresses the admin API of a self-hosted Kong through the instance's origin. It is the
+	// default for instances without type.
+	InstanceTypeAdminApi InstanceType = "admin-api"
+	// InstanceTypeKonnect addresses a Kong Konnect control plane through the Konnect API.
+	InstanceTypeKonnect InstanceType = "konnect"
+)
+
+// Konnect references a Kong Konnect control plane. Its core entities are managed through
+// https://<region>.api.konghq.com/v2/control-planes/<id>/core-entities, authenticated by a personal or system
+// access token.
+type Konnect struct {
+	Region string `json:"region" yaml:"region"`
+	// ControlPlaneId or ControlPlaneName identify the control plane, a name is resolved to its ID once per instance configuration.
+	ControlPlaneId   string `json:"controlPlaneId" yaml:"controlPlaneId"`
+	ControlPlaneName string `json:"controlPlaneName" yaml:"controlPlaneName"`
+	Token            string `json:"token" yaml:"token"`
+	// TokenFile optionally points to a file holding the token, which is re-read whenever the file changes.
+	TokenFile string `json:"tokenFile" yaml:"tokenFile"`
+	// ApiUrl overrides the regional Konnect API URL, e.g., to use a stand-in for testing.
+	ApiUrl string `json:"apiUrl" yaml:"apiUrl"`
+}
+
+func (i *Instance) IsKonnect() bool {
+	return i.Type == InstanceTypeKonnect
+}
+
+// Origin describes where the instance is reachable, for log and error messages.
+func (i *Instance) Origin() string {
+	if !i.IsKonnect() {
+		return i.BaseUrl
+	}
+	controlPlane := i.Konnect.ControlPlaneId
+	if len(controlPlane) == 0 {
+		controlPlane = i.Konnect.ControlPlaneName
+	}
+	return fmt.Sprintf("Konnect control plane %s at %s", controlPlane, i.Konnect.apiUrl())
+}
+
+func (k *Konnect) apiUrl() string {
+	if len(k.ApiUrl) > 0 {
+		return strings.TrimSuffix(k.ApiUrl, "/")
+	}
+	return fmt.Sprintf("https://%s.api.konghq.com", k.Region)
+}
+
+// failedControlPlaneLookupTtl defines how long a failed lookup of a control plane by name is cached, so that a
+// misconfigured instance doesn't call the Konnect API for every request.
+const failedControlPlaneLookupTtl = 30 * time.Second
+
+type controlPlaneLookup struct {
+	id         string
+	err        error
+	resolvedAt time.Time
+}
+
+// controlPlaneIds caches the control plane IDs resolved from names by instance fingerprint.
+var controlPlaneIds sync.Map
+
+// controlPlaneUrl returns the URL under which the control plane's core entities are managed. A control plane
+// configured by name is looked up through the Konnect API once per instance configuration.
+func (k *Konnect) controlPlaneUrl(ctx context.Context, fingerprint string, client *http.Client) (string, error) {
+	id := k.ControlPlaneId
+	if len(id) == 0 {
+		var err error
+		id, err = k.resolveControlPlaneId(ctx, fingerprint, client)
+		if err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%s/v2/control-planes/%s/core-entities", k.apiUrl(), url.PathEscape(id)), nil
+}
+
+func (k *Konnect) resolveControlPlaneId(ctx context.Context, fingerprint string, client *http.Client) (string, error) {
+	if cached, ok := controlPlaneIds.Load(fingerprint); ok {
+		lookup := cached.(controlPlaneLookup)
+		if lookup.err == nil || time.Since(lookup.resolvedAt) < failedControlPlaneLookupTtl {
+			return lookup.id, lookup.err
+		}
+	}
+
+	id, err := k.findControlPlaneId(ctx, client)
+	if err != nil && ctx.Err() != nil {
+		// the caller gave up, which says nothing about the control plane
+		return "", err
+	}
+	controlPlaneIds.Store(fingerprint, controlPlaneLookup{id: id, err: err, resolvedAt: time.Now()})
+	return id, err
+}
+
+func (k *Konnect) findControlPlaneId(ctx context.Context, client *http.Client) (string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := url.Values{"filter[name][eq]": {k.ControlPlaneName}}
+	controlPlanes, err := call(ctx, true, func(ctx context.Context) ([]konnectControlPlane, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.apiUrl()+"/v2/control-planes?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = res.Body.Close() }()
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %d listing Konnect control planes", res.StatusCode)
+		}
+		var page struct {
+			Data []konnectControlPlane `json:"data"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+			return nil, err
+		}
+		return page.Data, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to look up Konnect control plane '%s': %w", k.ControlPlaneName, err)
+	}
+	for _, controlPlane := range controlPlanes {
+		if controlPlane.Name == k.ControlPlaneName {
+			return controlPlane.Id, nil
+		}
+	}
+	return "", fmt.Errorf("no Konnect control plane named '%s' found", k.ControlPlaneName)
+}
+
+type konnectControlPlane struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// validate reports all problems of the Konnect settings at once.
+func (k *Konnect) validate(instance *Instance) []error {
+	var errs []error
+	if len(k.Region) == 0 && len(k.ApiUrl) == 0 {
+		errs = append(errs, fmt.Errorf("instance '%s' must configure a Konnect region", instance.Name))
+	}
+	if len(k.ApiUrl) > 0 && !isHttpUrl(k.ApiUrl) {
+		errs = append(errs, fmt.Errorf("instance '%s' has a malformed Konnect API URL '%s', expected an absolute http(s) URL", instance.Name, k.ApiUrl))
+	}
+	if len(k.ControlPlaneId) == 0 && len(k.ControlPlaneName) == 0 {
+		errs = append(errs, fmt.Errorf("instance '%s' must configure a Konnect control plane ID or name", instance.Name))
+	}
+	if (len(k.Token) == 0) == (len(k.TokenFile) == 0) {
+		errs = append(errs, fmt.Errorf("instance '%s' must configure either a Konnect token or a token file", instance.Name))
+	}
+	if instance.UsesWorkspaces() {
+		errs = append(errs, fmt.Errorf("instance '%s' uses Konnect, which doesn't support workspaces", instance.Name))
+	}
+	if instance.UsesBasicAuth() || len(instance.HeaderKey) > 0 {
+		errs = append(errs, fmt.Errorf("instance '%s' authenticates through its Konnect token, header key and basic auth are not supported", instance.Name))
+	}
+	return errs
+}
@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// konnectStandIn answers the control plane lookup and the core entities of a single control plane. It counts the
+// lookups of control planes.
+func konnectStandIn(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	var lookups atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Authorization") != "Bearer kpat_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"Unauthorized"}`))
+			return
+		}
+		if r.URL.Path == "/v2/control-planes" {
+			lookups.Add(1)
+		}
+		switch {
+		case r.URL.Path == "/v2/control-planes" && r.URL.Query().Get("filter[name][eq]") == "production":
+			_, _ = w.Write([]byte(`{"data":[{"id":"cp-1","name":"production"}]}`))
+		case r.URL.Path == "/v2/control-planes":
+			_, _ = w.Write([]byte(`{"data":[]}`))
+		case r.URL.Path == "/v2/control-planes/cp-1/core-entities/services/service":
+			_, _ = w.Write([]byte(`{"id":"service","name":"service"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not found"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &lookups
+}
+
+func TestKonnectInstanceResolvesControlPlaneByName(t *testing.T) {
+	server, _ := konnectStandIn(t)
+	instance := Instance{Name: t.Name(), Type: InstanceTypeKonnect, Konnect: Konnect{ControlPlaneName: "production", Token: "kpat_token", ApiUrl: server.URL}}
+
+	service, err := instance.FindService(context.Background(), new("service"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "service", *service.ID)
+}
+
+func TestKonnectInstanceFailsForUnknownControlPlane(t *testing.T) {
+	server, _ := konnectStandIn(t)
+	instance := Instance{Name: t.Name(), Type: InstanceTypeKonnect, Konnect: Konnect{ControlPlaneName: "staging", Token: "kpat_token", ApiUrl: server.URL}}
+
+	_, err := instance.FindService(context.Background(), new("service"))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no Konnect control plane named 'staging' found")
+}
+
+func TestKonnectInstanceCachesFailedControlPlaneLookups(t *testing.T) {
+	server, lookups := konnectStandIn(t)
+	instance := Instance{Name: t.Name(), Type: InstanceTypeKonnect, Konnect: Konnect{ControlPlaneName: "staging", Token: "kpat_token", ApiUrl: server.URL}}
+
+	_, err := instance.GetClient(context.Background())
+	require.Error(t, err)
+	_, err = instance.GetClient(context.Background())
+	require.Error(t, err)
+
+	assert.Equal(t, int32(1), lookups.Load())
+}
+
+func TestKonnectInstanceLooksUpControlPlaneWithCallerContext(t *testing.T) {
+	server, lookups := konnectStandIn(t)
+	instance := Instance{Name: t.Name(), Type: InstanceTypeKonnect, Konnect: Konnect{ControlPlaneName: "production", Token: "kpat_token", ApiUrl: server.URL}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := instance.GetClient(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(0), lookups.Load())
+
+	// a canceled lookup isn't cached
+	_, err = instance.GetClient(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), lookups.Load())
+}
+
+func TestKonnectInstanceUsesRegionalApi(t *testing.T) {
+	instance := Instance{Name: "konnect", Type: InstanceTypeKonnect, Konnect: Konnect{Region: "eu", ControlPlaneId: "cp-1", Token: "kpat_token"}}
+
+	client, err := instance.GetClient(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://eu.api.konghq.com/v2/control-planes/cp-1/core-entities", client.BaseRootURL())
+	assert.NoError(t, validateInstances([]Instance{instance}))
+}
+
+func TestValidateInstancesReportsIncompleteKonnectSettings(t *testing.T) {
+	err := validateInstances([]Instance{
+		{Name: "konnect", Type: InstanceTypeKonnect, Workspaces: []string{"team-a"}},
+		{Name: "unknown", Type: "cloud", BaseUrl: "http://kong:8001"},
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "instance 'konnect' must configure a Konnect region")
+	assert.Contains(t, err.Error(), "instance 'konnect' must configure a Konnect control plane ID or name")
+	assert.Contains(t, err.Error(), "instance 'konnect' must configure either a Konnect token or a token file")
+	assert.Contains(t, err.Error(), "instance 'konnect' uses Konnect, which doesn't support workspaces")
+	assert.Contains(t, err.Error(), "instance 'unknown' has the unknown type 'cloud'")
+}
@@ -0,0 +1,221 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// PluginsAnnotation lists the KongPlugin resources the Kong Ingress Controller applies to an Ingress, Service or
+// HTTPRoute.
+const PluginsAnnotation = "konghq.com/plugins"
+
+// maxAnnotationAttempts bounds how often updating an annotation is attempted while the object keeps changing
+// concurrently.
+const maxAnnotationAttempts = 3
+
+// KongPluginResource is the custom resource of the Kong Ingress Controller configuring a plugin.
+var KongPluginResource = schema.GroupVersionResource{Group: "configuration.konghq.com", Version: "v1", Resource: "kongplugins"}
+
+// KubernetesObject references a Kubernetes object the Kong Ingress Controller generates Kong entities from.
+type KubernetesObject struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// kubernetesResources maps the kinds which can be annotated with PluginsAnnotation to their resources.
+var kubernetesResources = map[string]schema.GroupVersionResource{
+	"Ingress":   {Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+	"Service":   {Version: "v1", Resource: "services"},
+	"HTTPRoute": {Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"},
+}
+
+func (o KubernetesObject) String() string {
+	return fmt.Sprintf("%s %s/%s", o.Kind, o.Namespace, o.Name)
+}
+
+func (o KubernetesObject) resource() (schema.GroupVersionResource, error) {
+	resource, ok := kubernetesResources[o.Kind]
+	if !ok {
+		return schema.GroupVersionResource{}, fmt.Errorf("plugins cannot be attached to objects of kind '%s'", o.Kind)
+	}
+	return resource, nil
+}
+
+// KubernetesClient manages KongPlugin resources and annotations through the Kubernetes API.
+type KubernetesClient struct {
+	dynamic dynamic.Interface
+}
+
+// NewKubernetesClient returns a client using the given dynamic client, e.g., a fake one.
+func NewKubernetesClient(client dynamic.Interface) *KubernetesClient {
+	return &KubernetesClient{dynamic: client}
+}
+
+var (
+	kubernetesClientMu  sync.Mutex
+	kubernetesClient    *KubernetesClient
+	kubernetesClientKey string
+)
+
+// GetKubernetesClient returns the client for the cluster the extension runs in, authenticated by the token of its
+// service account. The token is read again whenever it gets rotated.
+func GetKubernetesClient() (*KubernetesClient, error) {
+	kubernetesClientMu.Lock()
+	defer kubernetesClientMu.Unlock()
+
+	host := Config.KubernetesApiUrl
+	if len(host) == 0 {
+		serviceHost, servicePort := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if len(serviceHost) == 0 || len(servicePort) == 0 {
+			return nil, errors.New("the extension doesn't run within Kubernetes, please configure STEADYBIT_EXTENSION_KUBERNETES_API_URL")
+		}
+		host = "https://" + net.JoinHostPort(serviceHost, servicePort)
+	}
+
+	key := strings.Join([]string{host, Config.KubernetesTokenFile, Config.KubernetesCaFile}, "\x00")
+	if kubernetesClient != nil && kubernetesClientKey == key {
+		return kubernetesClient, nil
+	}
+
+	restConfig := &rest.Config{Host: host, UserAgent: "steadybit-extension-kong", Timeout: Config.AdminApiTimeout}
+	if _, err := os.Stat(Config.KubernetesTokenFile); err == nil {
+		restConfig.BearerTokenFile = Config.KubernetesTokenFile
+	}
+	if _, err := os.Stat(Config.KubernetesCaFile); err == nil {
+		restConfig.CAFile = Config.KubernetesCaFile
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	kubernetesClient = NewKubernetesClient(dynamicClient)
+	kubernetesClientKey = key
+	return kubernetesClient, nil
+}
+
+// CreateKongPlugin creates a KongPlugin resource. A resource which already exists is left as it is, which makes
+// the creation safe to retry.
+func (c *KubernetesClient) CreateKongPlugin(ctx context.Context, namespace string, name string, plugin string, pluginConfig map[string]any) error {
+	// unstructured objects only hold JSON values, e.g., no ints
+	b, err := json.Marshal(pluginConfig)
+	if err != nil {
+		return err
+	}
+	var configuration map[string]any
+	if err := json.Unmarshal(b, &configuration); err != nil {
+		return err
+	}
+	resource := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": KongPluginResource.GroupVersion().String(),
+		"kind":       "KongPlugin",
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": namespace,
+			"labels":    map[string]any{"app.kubernetes.io/managed-by": "steadybit"},
+		},
+		"plugin": plugin,
+		"config": configuration,
+	}}
+	_, err = c.dynamic.Resource(KongPluginResource).Namespace(namespace).Create(ctx, resource, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+// DeleteKongPlugin deletes a KongPlugin resource, resources which don't exist anymore are ignored.
+func (c *KubernetesClient) DeleteKongPlugin(ctx context.Context, namespace string, name string) error {
+	err := c.dynamic.Resource(KongPluginResource).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// AddPluginAnnotation adds the KongPlugin to the plugins the object is annotated with.
+func (c *KubernetesClient) AddPluginAnnotation(ctx context.Context, object KubernetesObject, plugin string) error {
+	return c.updatePluginAnnotation(ctx, object, func(plugins []string) []string {
+		if slices.Contains(plugins, plugin) {
+			return plugins
+		}
+		return append(plugins, plugin)
+	})
+}
+
+// RemovePluginAnnotation removes the KongPlugin from the plugins the object is annotated with. Objects which don't
+// exist anymore are ignored.
+func (c *KubernetesClient) RemovePluginAnnotation(ctx context.Context, object KubernetesObject, plugin string) error {
+	err := c.updatePluginAnnotation(ctx, object, func(plugins []string) []string {
+		return slices.DeleteFunc(plugins, func(p string) bool { return p == plugin })
+	})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// updatePluginAnnotation changes the PluginsAnnotation of the object. The update is bound to the version of the
+// object it was computed from, so that concurrent changes of the annotation aren't overwritten.
+func (c *KubernetesClient) updatePluginAnnotation(ctx context.Context, object KubernetesObject, update func([]string) []string) error {
+	resource, err := object.resource()
+	if err != nil {
+		return err
+	}
+	objects := c.dynamic.Resource(resource).Namespace(object.Namespace)
+
+	for attempt := 1; ; attempt++ {
+		current, err := objects.Get(ctx, object.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		annotations := current.GetAnnotations()
+		var plugins []string
+		for plugin := range strings.SplitSeq(annotations[PluginsAnnotation], ",") {
+			if plugin = strings.TrimSpace(plugin); len(plugin) > 0 {
+				plugins = append(plugins, plugin)
+			}
+		}
+		updated := update(slices.Clone(plugins))
+		if slices.Equal(plugins, updated) {
+			return nil
+		}
+
+		var annotation any
+		if len(updated) > 0 {
+			annotation = strings.Join(updated, ",")
+		}
+		patch, err := json.Marshal(map[string]any{
+			"metadata": map[string]any{
+				"resourceVersion": current.GetResourceVersion(),
+				"annotations":     map[string]any{PluginsAnnotation: annotation},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		_, err = objects.Patch(ctx, object.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		if !apierrors.IsConflict(err) || attempt >= maxAnnotationAttempts {
+			return err
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"testing"
+)
+
+func cartService(annotations map[string]any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]any{"name": "cart", "namespace": "shop", "annotations": annotations},
+	}}
+}
+
+func TestAddPluginAnnotationRetriesOnConflict(t *testing.T) {
+	services := kubernetesResources["Service"]
+	dynamicClient := fake.NewSimpleDynamicClient(runtime.NewScheme(), cartService(map[string]any{PluginsAnnotation: "rate-limit"}))
+	patches := 0
+	dynamicClient.PrependReactor("patch", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patches++
+		if patches > 1 {
+			return false, nil, nil
+		}
+		// somebody else annotates the service after the first read
+		if err := dynamicClient.Tracker().Update(services, cartService(map[string]any{PluginsAnnotation: "rate-limit,cors"}), "shop"); err != nil {
+			return true, nil, err
+		}
+		return true, nil, apierrors.NewConflict(services.GroupResource(), "cart", errors.New("the object has been modified"))
+	})
+
+	err := NewKubernetesClient(dynamicClient).AddPluginAnnotation(context.Background(), KubernetesObject{Kind: "Service", Namespace: "shop", Name: "cart"}, "steadybit")
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, patches)
+	service, err := dynamicClient.Resource(services).Namespace("shop").Get(context.Background(), "cart", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "rate-limit,cors,steadybit", service.GetAnnotations()[PluginsAnnotation])
+}
+
+func TestCreateKongPluginIgnoresExistingPlugins(t *testing.T) {
+	client := NewKubernetesClient(fake.NewSimpleDynamicClient(runtime.NewScheme()))
+
+	require.NoError(t, client.CreateKongPlugin(context.Background(), "shop", "steadybit", "request-termination", map[string]any{"status_code": 503}))
+	require.NoError(t, client.CreateKongPlugin(context.Background(), "shop", "steadybit", "request-termination", map[string]any{"status_code": 503}))
+	require.NoError(t, client.DeleteKongPlugin(context.Background(), "shop", "steadybit"))
+	require.NoError(t, client.DeleteKongPlugin(context.Background(), "shop", "steadybit"))
+}
+
+func TestPluginsCannotBeAttachedToUnsupportedKinds(t *testing.T) {
+	client := NewKubernetesClient(fake.NewSimpleDynamicClient(runtime.NewScheme()))
+
+	err := client.AddPluginAnnotation(context.Background(), KubernetesObject{Kind: "TCPRoute", Namespace: "shop", Name: "cart"}, "steadybit")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "plugins cannot be attached to objects of kind 'TCPRoute'")
+}
@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MetricSample is a single sample of the Prometheus metrics exposed by Kong.
+type MetricSample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// Key identifies the series of the sample, i.e., its name together with all labels.
+func (s MetricSample) Key() string {
+	var key strings.Builder
+	key.WriteString(s.Name)
+	for _, name := range slices.Sorted(maps.Keys(s.Labels)) {
+		key.WriteString(fmt.Sprintf(",%s=%q", name, s.Labels[name]))
+	}
+	return key.String()
+}
+
+// GetMetrics scrapes the Prometheus metrics of the node answering the admin API. Kong only exposes them while the
+// prometheus plugin is enabled.
+func (i *Instance) GetMetrics(ctx context.Context) ([]MetricSample, error) {
+	if i.IsKonnect() {
+		return nil, errors.New("scraping the metrics of Konnect control planes isn't supported")
+	}
+	client, err := i.GetClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := call(ctx, true, func(ctx context.Context) (*bytes.Buffer, error) {
+		// metrics are exposed for the whole node, independent of workspaces
+		req, err := client.NewRequestRaw(http.MethodGet, client.BaseRootURL(), "/metrics", nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var body bytes.Buffer
+		_, err = client.Do(ctx, req, &body)
+		return &body, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parsePrometheusText(body)
+}
+
+// parsePrometheusText parses the samples of the Prometheus text exposition format. Histograms and summaries are
+// flattened into the series of the text format, e.g., _bucket, _sum and _count.
+func parsePrometheusText(r io.Reader) ([]MetricSample, error) {
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var samples []MetricSample
+	for _, name := range slices.Sorted(maps.Keys(families)) {
+		for _, metric := range families[name].GetMetric() {
+			samples = append(samples, samplesOf(name, metric)...)
+		}
+	}
+	return samples, nil
+}
+
+func samplesOf(name string, metric *dto.Metric) []MetricSample {
+	sample := func(name string, value float64, extraLabels ...string) MetricSample {
+		labels := make(map[string]string, len(metric.GetLabel())+len(extraLabels)/2)
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+		for i := 0; i+1 < len(extraLabels); i += 2 {
+			labels[extraLabels[i]] = extraLabels[i+1]
+		}
+		return MetricSample{Name: name, Labels: labels, Value: value}
+	}
+
+	switch {
+	case metric.Counter != nil:
+		return []MetricSample{sample(name, metric.GetCounter().GetValue())}
+	case metric.Gauge != nil:
+		return []MetricSample{sample(name, metric.GetGauge().GetValue())}
+	case metric.Histogram != nil:
+		histogram := metric.GetHistogram()
+		var samples []MetricSample
+		hasInf := false
+		for _, bucket := range histogram.GetBucket() {
+			hasInf = hasInf || math.IsInf(bucket.GetUpperBound(), 1)
+			samples = append(samples, sample(name+"_bucket", float64(bucket.GetCumulativeCount()), model.BucketLabel, formatBound(bucket.GetUpperBound())))
+		}
+		if !hasInf {
+			samples = append(samples, sample(name+"_bucket", float64(histogram.GetSampleCount()), model.BucketLabel, formatBound(math.Inf(1))))
+		}
+		return append(samples,
+			sample(name+"_sum", histogram.GetSampleSum()),
+			sample(name+"_count", float64(histogram.GetSampleCount())),
+		)
+	case metric.Summary != nil:
+		summary := metric.GetSummary()
+		var samples []MetricSample
+		for _, quantile := range summary.GetQuantile() {
+			samples = append(samples, sample(name, quantile.GetValue(), model.QuantileLabel, formatBound(quantile.GetQuantile())))
+		}
+		return append(samples,
+			sample(name+"_sum", summary.GetSampleSum()),
+			sample(name+"_count", float64(summary.GetSampleCount())),
+		)
+	default:
+		return []MetricSample{sample(name, metric.GetUntyped().GetValue())}
+	}
+}
+
+// formatBound formats a bucket bound or quantile like the text format does, e.g., +Inf.
+func formatBound(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const kongMetrics = `# HELP kong_http_requests_total HTTP status codes per consumer/service/route in Kong
+# TYPE kong_http_requests_total counter
+kong_http_requests_total{service="products",route="products-get",code="200",source="service",workspace="default",consumer=""} 42
+kong_http_requests_total{service="products",route="products-get",code="503",source="kong",workspace="default",consumer=""} 3 1700000000000
+kong_upstream_latency_ms_bucket{service="products",route="products-get",workspace="default",le="+Inf"} 42
+kong_datastore_reachable 1
+kong_nginx_metric_errors_total{message="say \"hi\"\\n",} 0
+`
+
+func TestParsePrometheusText(t *testing.T) {
+	samples, err := parsePrometheusText(strings.NewReader(kongMetrics))
+
+	require.NoError(t, err)
+	require.Len(t, samples, 5)
+	assert.Equal(t, MetricSample{Name: "kong_datastore_reachable", Labels: map[string]string{}, Value: 1}, samples[0])
+	assert.Equal(t, MetricSample{
+		Name:   "kong_http_requests_total",
+		Labels: map[string]string{"service": "products", "route": "products-get", "code": "503", "source": "kong", "workspace": "default", "consumer": ""},
+		Value:  3,
+	}, samples[2])
+	assert.Equal(t, `say "hi"\n`, samples[3].Labels["message"])
+	assert.Equal(t, "+Inf", samples[4].Labels["le"])
+}
+
+func TestParsePrometheusTextFlattensHistograms(t *testing.T) {
+	samples, err := parsePrometheusText(strings.NewReader(`# TYPE kong_upstream_latency_ms histogram
+kong_upstream_latency_ms_bucket{service="products",le="25"} 3
+kong_upstream_latency_ms_bucket{service="products",le="+Inf"} 4
+kong_upstream_latency_ms_sum{service="products"} 130
+kong_upstream_latency_ms_count{service="products"} 4
+# TYPE kong_memory_workers_lua_vms_bytes gauge
+kong_memory_workers_lua_vms_bytes{pid="1"} +Inf
+kong_memory_workers_lua_vms_bytes{pid="2"} NaN
+`))
+
+	require.NoError(t, err)
+	require.Len(t, samples, 6)
+	assert.True(t, math.IsInf(samples[0].Value, 1))
+	assert.True(t, math.IsNaN(samples[1].Value))
+	assert.Equal(t, MetricSample{Name: "kong_upstream_latency_ms_bucket", Labels: map[string]string{"service": "products", "le": "25"}, Value: 3}, samples[2])
+	assert.Equal(t, MetricSample{Name: "kong_upstream_latency_ms_bucket", Labels: map[string]string{"service": "products", "le": "+Inf"}, Value: 4}, samples[3])
+	assert.Equal(t, MetricSample{Name: "kong_upstream_latency_ms_sum", Labels: map[string]string{"service": "products"}, Value: 130}, samples[4])
+	assert.Equal(t, MetricSample{Name: "kong_upstream_latency_ms_count", Labels: map[string]string{"service": "products"}, Value: 4}, samples[5])
+}
+
+func TestParsePrometheusTextRejectsMalformedSamples(t *testing.T) {
+	_, err := parsePrometheusText(strings.NewReader("kong_datastore_reachable{up=\"1} 1\n"))
+	assert.ErrorContains(t, err, "line 1")
+
+	_, err = parsePrometheusText(strings.NewReader("kong_datastore_reachable one\n"))
+	assert.ErrorContains(t, err, "expected float as value")
+}
+
+func TestMetricSampleKeyIsIndependentOfLabelOrder(t *testing.T) {
+	a := MetricSample{Name: "kong_http_requests_total", Labels: map[string]string{"service": "a", "code": "200"}}
+	b := MetricSample{Name: "kong_http_requests_total", Labels: map[string]string{"code": "200", "service": "a"}}
+
+	assert.Equal(t, a.Key(), b.Key())
+}
+
+func TestGetMetricsScrapesAdminApi(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write([]byte(kongMetrics))
+	}))
+	t.Cleanup(server.Close)
+	instance := &Instance{Name: t.Name(), BaseUrl: server.URL}
+
+	samples, err := instance.GetMetrics(context.Background())
+
+	require.NoError(t, err)
+	assert.Len(t, samples, 5)
+}
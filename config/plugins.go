@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// availablePluginsTtl defines how long the plugins available on an instance are cached. Making a plugin available
+// requires a restart of Kong, so they rarely change.
+const availablePluginsTtl = time.Minute
+
+type cachedPlugins struct {
+	plugins   []string
+	fetchedAt time.Time
+}
+
+var availablePlugins sync.Map
+
+// GetAvailablePlugins returns the plugins installed on the node answering the admin API, i.e., listed by the
+// `plugins` setting of Kong. It returns nil if the instance doesn't report them, e.g., for Konnect control planes.
+func (i *Instance) GetAvailablePlugins(ctx context.Context) ([]string, error) {
+	if i.IsKonnect() {
+		return nil, nil
+	}
+
+	fingerprint, err := i.fingerprint()
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := availablePlugins.Load(fingerprint); ok && time.Since(cached.(cachedPlugins).fetchedAt) < availablePluginsTtl {
+		return cached.(cachedPlugins).plugins, nil
+	}
+
+	root, err := i.getRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	plugins := availablePluginsOf(root)
+	availablePlugins.Store(fingerprint, cachedPlugins{plugins: plugins, fetchedAt: time.Now()})
+	return plugins, nil
+}
+
+// SupportsPlugin tells whether the plugin is available on the instance. Instances which don't report their plugins
+// are assumed to support it.
+func (i *Instance) SupportsPlugin(ctx context.Context, plugin string) (bool, error) {
+	plugins, err := i.GetAvailablePlugins(ctx)
+	if err != nil {
+		return false, err
+	}
+	return plugins == nil || slices.Contains(plugins, plugin), nil
+}
+
+func availablePluginsOf(root map[string]any) []string {
+	if plugins, ok := root["plugins"].(map[string]any); ok {
+		// available_on_server maps plugin names to their version and priority, or to true for older versions of Kong
+		if available, ok := plugins["available_on_server"].(map[string]any); ok {
+			return slices.Sorted(maps.Keys(available))
+		}
+	}
+	return nil
+}
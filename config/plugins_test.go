@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func pluginsKong(t *testing.T, root string) (*Instance, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			calls.Add(1)
+			_, _ = w.Write([]byte(root))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"Not found"}`))
+	}))
+	t.Cleanup(server.Close)
+	return &Instance{Name: t.Name(), BaseUrl: server.URL}, &calls
+}
+
+func TestSupportsPluginChecksAvailablePlugins(t *testing.T) {
+	instance, calls := pluginsKong(t, `{"version":"3.9.0","plugins":{"available_on_server":{"cors":true,"request-termination":{"version":"3.9.0","priority":2}}}}`)
+
+	supported, err := instance.SupportsPlugin(context.Background(), "request-termination")
+	require.NoError(t, err)
+	assert.True(t, supported)
+
+	supported, err = instance.SupportsPlugin(context.Background(), "rate-limiting")
+	require.NoError(t, err)
+	assert.False(t, supported)
+
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestSupportsPluginAssumesUnreportedPluginsAreAvailable(t *testing.T) {
+	instance, _ := pluginsKong(t, `{"version":"3.9.0"}`)
+
+	supported, err := instance.SupportsPlugin(context.Background(), "request-termination")
+
+	require.NoError(t, err)
+	assert.True(t, supported)
+}
+
+func TestPruneClientsDropsAvailablePluginsOfUnusedInstances(t *testing.T) {
+	instance, _ := pluginsKong(t, `{"version":"3.9.0","plugins":{"available_on_server":{"cors":true}}}`)
+	_, err := instance.GetAvailablePlugins(context.Background())
+	require.NoError(t, err)
+
+	pruneClients()
+
+	fingerprint, err := instance.fingerprint()
+	require.NoError(t, err)
+	_, cached := availablePlugins.Load(fingerprint)
+	assert.False(t, cached)
+}
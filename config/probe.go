@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"github.com/kong/go-kong/kong"
+	"slices"
+)
+
+// Editions of Kong Gateway.
+const (
+	EditionCommunity  = "community"
+	EditionEnterprise = "enterprise"
+)
+
+// InstanceInfo summarizes what the admin API of a Kong instance reports about itself. Everything but the version,
+// database and its reachability is only reported by self-hosted nodes.
+type InstanceInfo struct {
+	Version string
+	// Database is the configured database, "off" for DB-less deployments.
+	Database          string
+	DatabaseReachable bool
+	NodeId            string
+	Hostname          string
+	Edition           string
+	// Role is "traditional", "control_plane" or "data_plane".
+	Role string
+	// AvailablePlugins are installed on the node, EnabledPlugins are configured somewhere in the cluster.
+	AvailablePlugins []string
+	EnabledPlugins   []string
+	Connections      Connections
+}
+
+// Connections are the connection counters of the node's server.
+type Connections struct {
+	Active        int
+	Accepted      int
+	Handled       int
+	Reading       int
+	Writing       int
+	Waiting       int
+	TotalRequests int
+}
+
+// Probe checks that the admin API of the instance is reachable and reports its version and database mode.
+func (i *Instance) Probe(ctx context.Context) (*InstanceInfo, error) {
+	client, err := i.GetClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if i.IsKonnect() {
+		// Konnect neither exposes the status nor the node information of a control plane
+		if _, _, err := listServices(ctx, client, &kong.ListOpt{Size: 1}); err != nil {
+			return nil, fmt.Errorf("failed to list services: %w", err)
+		}
+		return &InstanceInfo{Version: "Konnect", Database: "konnect", DatabaseReachable: true}, nil
+	}
+
+	status, err := call(ctx, true, func(ctx context.Context) (*kong.Status, error) {
+		return client.Status(ctx)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the status: %w", err)
+	}
+
+	root, err := call(ctx, true, func(ctx context.Context) (map[string]any, error) {
+		return client.Root(ctx)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the node information: %w", err)
+	}
+
+	info := &InstanceInfo{
+		Version:           kong.VersionFromInfo(root),
+		Database:          databaseOf(root),
+		DatabaseReachable: status.Database.Reachable,
+		NodeId:            stringOf(root["node_id"]),
+		Hostname:          stringOf(root["hostname"]),
+		Edition:           EditionCommunity,
+		Connections: Connections{
+			Active:        status.Server.ConnectionsActive,
+			Accepted:      status.Server.ConnectionsAccepted,
+			Handled:       status.Server.ConnectionsHandled,
+			Reading:       status.Server.ConnectionsReading,
+			Writing:       status.Server.ConnectionsWriting,
+			Waiting:       status.Server.ConnectionsWaiting,
+			TotalRequests: status.Server.TotalRequests,
+		},
+	}
+	if version, err := kong.NewVersion(info.Version); err == nil && version.IsKongGatewayEnterprise() {
+		info.Edition = EditionEnterprise
+	}
+	if configuration, ok := root["configuration"].(map[string]any); ok {
+		info.Role = stringOf(configuration["role"])
+	}
+	info.AvailablePlugins = availablePluginsOf(root)
+	if plugins, ok := root["plugins"].(map[string]any); ok {
+		if enabled, ok := plugins["enabled_in_cluster"].([]any); ok {
+			for _, plugin := range enabled {
+				info.EnabledPlugins = append(info.EnabledPlugins, stringOf(plugin))
+			}
+			slices.Sort(info.EnabledPlugins)
+		}
+	}
+	return info, nil
+}
+
+func stringOf(value any) string {
+	s, _ := value.(string)
+	return s
+}
@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"errors"
+	"github.com/kong/go-kong/kong"
+	"github.com/rs/zerolog/log"
+	"io"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"syscall"
+	"time"
+)
+
+// call executes a single admin API call bounded by the configured request timeout and retries transient
+// failures according to the configured retry policy. Calls which are not idempotent are only retried when
+// the request provably never reached Kong, i.e., when the connection could not be established.
+func call[T any](ctx context.Context, idempotent bool, fn func(ctx context.Context) (T, error)) (T, error) {
+	maxAttempts := max(Config.AdminApiRetryMaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		result, err := callWithTimeout(ctx, fn)
+		if err == nil || attempt >= maxAttempts || ctx.Err() != nil || !isRetryable(err, idempotent) {
+			return result, err
+		}
+
+		backoff := retryBackoff(attempt)
+		log.Debug().Err(err).Msgf("Kong admin API call failed (attempt %d of %d), retrying in %s", attempt, maxAttempts, backoff)
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(backoff):
+		}
+	}
+}
+
+func callWithTimeout[T any](ctx context.Context, fn func(ctx context.Context) (T, error)) (T, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	return fn(ctx)
+}
+
+func isRetryable(err error, idempotent bool) bool {
+	var apiErr *kong.APIError
+	if errors.As(err, &apiErr) {
+		return idempotent && slices.Contains(Config.AdminApiRetryStatusCodes, apiErr.Code())
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	if !idempotent {
+		return false
+	}
+	var netErr net.Error
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		(errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// retryBackoff grows exponentially with every attempt up to the configured maximum. Half of the backoff
+// is randomized to keep many extension calls from hitting a recovering Kong all at once.
+func retryBackoff(attempt int) time.Duration {
+	backoff := Config.AdminApiRetryInitialBackoff
+	for i := 1; i < attempt && backoff < Config.AdminApiRetryMaxBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, Config.AdminApiRetryMaxBackoff)
+	if backoff <= 0 {
+		return 0
+	}
+	half := backoff / 2
+	return half + rand.N(half+1)
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"github.com/kong/go-kong/kong"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func withRetryPolicy(t *testing.T) {
+	previous := Config
+	Config.AdminApiRetryMaxAttempts = 3
+	Config.AdminApiRetryInitialBackoff = time.Millisecond
+	Config.AdminApiRetryMaxBackoff = 5 * time.Millisecond
+	Config.AdminApiRetryStatusCodes = []int{502, 503, 504}
+	t.Cleanup(func() { Config = previous })
+}
+
+// flakyKong answers the first failures requests with a 503 and all following requests with the given body.
+func flakyKong(t *testing.T, failures int32, body string) (*Instance, *atomic.Int32) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if requests.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"message":"upstream unavailable"}`))
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return &Instance{Name: t.Name(), BaseUrl: server.URL}, &requests
+}
+
+func TestReadsAreRetriedOnTransientStatusCodes(t *testing.T) {
+	withRetryPolicy(t)
+	instance, requests := flakyKong(t, 2, `{"id":"service","name":"service"}`)
+
+	service, err := instance.FindService(context.Background(), new("service"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "service", *service.ID)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestRetriesStopAfterMaxAttempts(t *testing.T) {
+	withRetryPolicy(t)
+	instance, requests := flakyKong(t, 5, `{"id":"service","name":"service"}`)
+
+	_, err := instance.FindService(context.Background(), new("service"))
+
+	require.Error(t, err)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestPluginCreationWithoutIdIsNotRetried(t *testing.T) {
+	withRetryPolicy(t)
+	instance, requests := flakyKong(t, 1, `{"id":"plugin"}`)
+
+	_, err := instance.CreatePluginAtAnyLevel(context.Background(), &kong.Plugin{
+		Name:    new("request-termination"),
+		Service: &kong.Service{ID: new("service")},
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestPluginCreationWithIdIsRetried(t *testing.T) {
+	withRetryPolicy(t)
+	instance, requests := flakyKong(t, 1, `{"id":"plugin"}`)
+
+	plugin, err := instance.CreatePluginAtAnyLevel(context.Background(), &kong.Plugin{
+		ID:      new("plugin"),
+		Name:    new("request-termination"),
+		Service: &kong.Service{ID: new("service")},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "plugin", *plugin.ID)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestRetryBackoffIsCapped(t *testing.T) {
+	withRetryPolicy(t)
+
+	for attempt := 1; attempt < 10; attempt++ {
+		assert.LessOrEqual(t, retryBackoff(attempt), Config.AdminApiRetryMaxBackoff)
+	}
+}
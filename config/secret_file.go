@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// secretFile provides the content of a file holding a secret, e.g., a mounted Kubernetes secret or a file
+// rendered by a Vault agent. The file is read again as soon as its modification time or size changes, so
+// rotated secrets are picked up without a restart.
+type secretFile struct {
+	path string
+
+	mu      sync.Mutex
+	loaded  bool
+	modTime time.Time
+	size    int64
+	value   string
+}
+
+func (s *secretFile) read() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file '%s': %w", s.path, err)
+	}
+	if s.loaded && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return s.value, nil
+	}
+
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file '%s': %w", s.path, err)
+	}
+	s.loaded = true
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+	s.value = strings.TrimSpace(string(content))
+	return s.value, nil
+}
+
+// secretHeaderTransport adds a header whose value is read from a secret file to every request.
+type secretHeaderTransport struct {
+	key string
+	// prefix is put in front of the secret, e.g., "Bearer " for tokens sent as Authorization header.
+	prefix string
+	secret *secretFile
+	next   http.RoundTripper
+}
+
+func (t *secretHeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	value, err := t.secret.read()
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set(t.key, t.prefix+value)
+	return t.next.RoundTrip(req)
+}
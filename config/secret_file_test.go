@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHeaderValueFileIsReadAgainWhenRotated(t *testing.T) {
+	var mu sync.Mutex
+	var tokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		tokens = append(tokens, r.Header.Get("Kong-Admin-Token"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"service","name":"service"}`))
+	}))
+	t.Cleanup(server.Close)
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("initial\n"), 0o600))
+	instance := Instance{Name: t.Name(), BaseUrl: server.URL, HeaderKey: "Kong-Admin-Token", HeaderValueFile: path}
+
+	_, err := instance.FindService(context.Background(), new("service"))
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("rotated\n"), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now().Add(time.Minute), time.Now().Add(time.Minute)))
+	_, err = instance.FindService(context.Background(), new("service"))
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"initial", "rotated"}, tokens)
+}
+
+func TestValidateInstancesReportsConflictingHeaderValues(t *testing.T) {
+	err := validateInstances([]Instance{
+		{Name: "both", BaseUrl: "http://kong:8001", HeaderKey: "Kong-Admin-Token", HeaderValue: "secret", HeaderValueFile: filepath.Join(t.TempDir(), "token")},
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "instance 'both' must configure either a header value or a header value file")
+	assert.Contains(t, err.Error(), "instance 'both' has an unreadable header value file")
+}
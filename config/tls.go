@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TLS configures how the admin API of an instance is accessed via HTTPS. All files are expected in PEM format.
+type TLS struct {
+	// CaFile points to the CA certificates used to verify the admin API's certificate instead of the system's ones.
+	CaFile string `json:"caFile" yaml:"caFile"`
+	// ClientCertFile and ClientKeyFile point to the client certificate presented to admin APIs requiring mutual TLS.
+	ClientCertFile     string `json:"clientCertFile" yaml:"clientCertFile"`
+	ClientKeyFile      string `json:"clientKeyFile" yaml:"clientKeyFile"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify" yaml:"insecureSkipVerify"`
+}
+
+// clientConfig builds the TLS configuration of the instance's transport. It returns nil if nothing is configured,
+// which keeps Go's defaults in place.
+func (t *TLS) clientConfig() (*tls.Config, error) {
+	if *t == (TLS{}) {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: t.InsecureSkipVerify, // NOSONAR explicitly opted in for admin APIs with self-signed certificates
+	}
+
+	if len(t.CaFile) > 0 {
+		pem, err := os.ReadFile(t.CaFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file '%s': %w", t.CaFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA file '%s' contains no PEM encoded certificate", t.CaFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if (len(t.ClientCertFile) == 0) != (len(t.ClientKeyFile) == 0) {
+		return nil, errors.New("client certificate and key must be configured together")
+	}
+	if len(t.ClientCertFile) > 0 {
+		certificate, err := tls.LoadX509KeyPair(t.ClientCertFile, t.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate '%s': %w", t.ClientCertFile, err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	return config, nil
+}
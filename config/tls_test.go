@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// mutualTLSKong starts an HTTPS admin API stand-in which requires a client certificate issued by the returned CA.
+func mutualTLSKong(t *testing.T) (*httptest.Server, *x509.Certificate, *ecdsa.PrivateKey) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "client-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDer, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(caDer)
+	require.NoError(t, err)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"service","name":"service"}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server, ca, caKey
+}
+
+func writePem(t *testing.T, name string, blockType string, der []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return path
+}
+
+func writeClientCertificate(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "steadybit-extension-kong"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return writePem(t, "client.crt", "CERTIFICATE", der), writePem(t, "client.key", "EC PRIVATE KEY", keyDer)
+}
+
+func TestClientUsesCustomCaAndClientCertificate(t *testing.T) {
+	server, ca, caKey := mutualTLSKong(t)
+	certFile, keyFile := writeClientCertificate(t, ca, caKey)
+	instance := Instance{Name: t.Name(), BaseUrl: server.URL, TLS: TLS{
+		CaFile:         writePem(t, "ca.crt", "CERTIFICATE", server.Certificate().Raw),
+		ClientCertFile: certFile,
+		ClientKeyFile:  keyFile,
+	}}
+
+	service, err := instance.FindService(context.Background(), new("service"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "service", *service.ID)
+}
+
+func TestClientRejectsUnknownServerCertificate(t *testing.T) {
+	server, ca, caKey := mutualTLSKong(t)
+	certFile, keyFile := writeClientCertificate(t, ca, caKey)
+	instance := Instance{Name: t.Name(), BaseUrl: server.URL, TLS: TLS{ClientCertFile: certFile, ClientKeyFile: keyFile}}
+
+	_, err := instance.FindService(context.Background(), new("service"))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "certificate")
+}
+
+func TestClientSkipsVerificationIfRequested(t *testing.T) {
+	server, ca, caKey := mutualTLSKong(t)
+	certFile, keyFile := writeClientCertificate(t, ca, caKey)
+	instance := Instance{Name: t.Name(), BaseUrl: server.URL, TLS: TLS{ClientCertFile: certFile, ClientKeyFile: keyFile, InsecureSkipVerify: true}}
+
+	_, err := instance.FindService(context.Background(), new("service"))
+
+	require.NoError(t, err)
+}
+
+func TestClientPicksUpClientCertificateRotatedInPlace(t *testing.T) {
+	server, ca, caKey := mutualTLSKong(t)
+	certFile, keyFile := writeClientCertificate(t, ca, caKey)
+	instance := Instance{Name: t.Name(), BaseUrl: server.URL, TLS: TLS{
+		CaFile:         writePem(t, "ca.crt", "CERTIFICATE", server.Certificate().Raw),
+		ClientCertFile: certFile,
+		ClientKeyFile:  keyFile,
+	}}
+	client, err := instance.GetClient(context.Background())
+	require.NoError(t, err)
+
+	rotatedCertFile, rotatedKeyFile := writeClientCertificate(t, ca, caKey)
+	for source, target := range map[string]string{rotatedCertFile: certFile, rotatedKeyFile: keyFile} {
+		content, err := os.ReadFile(source)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(target, content, 0o600))
+		rotatedAt := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(target, rotatedAt, rotatedAt))
+	}
+
+	rotatedClient, err := instance.GetClient(context.Background())
+	require.NoError(t, err)
+	assert.NotSame(t, client, rotatedClient)
+	_, err = instance.FindService(context.Background(), new("service"))
+	require.NoError(t, err)
+}
+
+func TestValidateInstancesReportsUnusableTLSSettings(t *testing.T) {
+	err := validateInstances([]Instance{
+		{Name: "missing-ca", BaseUrl: "https://kong:8001", TLS: TLS{CaFile: filepath.Join(t.TempDir(), "missing.crt")}},
+		{Name: "missing-key", BaseUrl: "https://kong:8001", TLS: TLS{ClientCertFile: "client.crt"}},
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "instance 'missing-ca' has invalid TLS settings: failed to read CA file")
+	assert.Contains(t, err.Error(), "instance 'missing-key' has invalid TLS settings: client certificate and key must be configured together")
+}
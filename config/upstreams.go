@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"errors"
+	"github.com/kong/go-kong/kong"
+)
+
+// Health states Kong reports for the targets of an upstream.
+const (
+	TargetHealthy         = "HEALTHY"
+	TargetUnhealthy       = "UNHEALTHY"
+	TargetDnsError        = "DNS_ERROR"
+	TargetHealthchecksOff = "HEALTHCHECKS_OFF"
+)
+
+// FindUpstream returns the upstream with the given name or ID, e.g., the host of a service balancing its requests
+// through an upstream.
+func (i *Instance) FindUpstream(ctx context.Context, nameOrId *string) (*kong.Upstream, error) {
+	client, err := i.GetClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return call(ctx, true, func(ctx context.Context) (*kong.Upstream, error) {
+		return client.Upstreams.Get(ctx, nameOrId)
+	})
+}
+
+// GetUpstreamHealth returns the health of all targets of the upstream, as seen by the node answering the admin API.
+// Konnect control planes don't balance requests themselves and therefore don't know the health of targets.
+func (i *Instance) GetUpstreamHealth(ctx context.Context, upstreamNameOrId *string) ([]*kong.UpstreamNodeHealth, error) {
+	if i.IsKonnect() {
+		return nil, errors.New("the health of upstream targets isn't available for Konnect control planes")
+	}
+	client, err := i.GetClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return call(ctx, true, func(ctx context.Context) ([]*kong.UpstreamNodeHealth, error) {
+		return client.UpstreamNodeHealth.ListAll(ctx, upstreamNameOrId)
+	})
+}
+
+// IsAvailable tells whether Kong balances requests to a target in the given health state. Targets of upstreams
+// without health checks are always available.
+func IsAvailable(health string) bool {
+	return health == TargetHealthy || health == TargetHealthchecksOff
+}
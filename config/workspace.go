@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"github.com/kong/go-kong/kong"
+	"slices"
+)
+
+const (
+	// AllWorkspaces configures an instance to discover all Kong Enterprise workspaces through the admin API.
+	AllWorkspaces = "*"
+	// DefaultWorkspace is the name of the workspace calls without workspace address.
+	DefaultWorkspace = "default"
+)
+
+// InWorkspace returns a copy of the instance whose calls are scoped to the given Kong Enterprise workspace. An
+// empty name addresses the admin API without workspace, i.e., the default workspace.
+func (i *Instance) InWorkspace(workspace string) *Instance {
+	scoped := *i
+	scoped.workspace = workspace
+	return &scoped
+}
+
+// WorkspaceName returns the name of the workspace the instance's calls are scoped to.
+func (i *Instance) WorkspaceName() string {
+	if len(i.workspace) == 0 {
+		return DefaultWorkspace
+	}
+	return i.workspace
+}
+
+// UsesWorkspaces tells whether workspaces are configured for the instance. Instances without workspaces are
+// addressed without workspace, which also works for Kong OSS.
+func (i *Instance) UsesWorkspaces() bool {
+	return len(i.Workspaces) > 0
+}
+
+// GetWorkspaces returns the workspaces to discover. All workspaces are listed through the admin API if
+// configured, instances without workspaces yield a single empty name addressing the default workspace.
+func (i *Instance) GetWorkspaces(ctx context.Context) ([]string, error) {
+	if !i.UsesWorkspaces() {
+		return []string{""}, nil
+	}
+	if !slices.Contains(i.Workspaces, AllWorkspaces) {
+		return i.Workspaces, nil
+	}
+
+	client, err := i.InWorkspace("").GetClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	workspaces, err := call(ctx, true, func(ctx context.Context) ([]*kong.Workspace, error) {
+		return client.Workspaces.ListAll(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(workspaces))
+	for _, workspace := range workspaces {
+		if workspace.Name != nil {
+			names = append(names, *workspace.Name)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package config
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func workspacesKong(t *testing.T) *Instance {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/workspaces/":
+			_, _ = w.Write([]byte(`{"data":[{"name":"default"},{"name":"team-a"}]}`))
+		case "/team-a/services/service":
+			_, _ = w.Write([]byte(`{"id":"service","name":"service"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not found"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return &Instance{Name: t.Name(), BaseUrl: server.URL, Workspaces: []string{AllWorkspaces}}
+}
+
+func TestGetWorkspacesListsAllWorkspaces(t *testing.T) {
+	instance := workspacesKong(t)
+
+	workspaces, err := instance.GetWorkspaces(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default", "team-a"}, workspaces)
+}
+
+func TestGetWorkspacesDefaultsToNoWorkspace(t *testing.T) {
+	instance := Instance{Name: t.Name(), BaseUrl: "http://kong:8001"}
+
+	workspaces, err := instance.GetWorkspaces(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{""}, workspaces)
+	assert.Equal(t, DefaultWorkspace, instance.WorkspaceName())
+}
+
+func TestInWorkspaceScopesCalls(t *testing.T) {
+	instance := workspacesKong(t)
+
+	service, err := instance.InWorkspace("team-a").FindService(context.Background(), new("service"))
+	require.NoError(t, err)
+	assert.Equal(t, "service", *service.ID)
+
+	_, err = instance.FindService(context.Background(), new("service"))
+	assert.Error(t, err)
+}
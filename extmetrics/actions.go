@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extmetrics
+
+import (
+	"context"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-kong/v2/exttracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// InstrumentAction counts the outcome of every phase of the action and traces it. A phase fails if it returns an
+// error, results reporting a failed check count as success. The returned action supports status and stop if the given one does,
+// as the SDK derives the endpoints of an action from the interfaces it implements.
+func InstrumentAction[T any](action action_kit_sdk.Action[T]) action_kit_sdk.Action[T] {
+	instrumented := instrumentedAction[T]{action: action, id: action.Describe().Id}
+	_, withStatus := action.(action_kit_sdk.ActionWithStatus[T])
+	_, withStop := action.(action_kit_sdk.ActionWithStop[T])
+	switch {
+	case withStatus && withStop:
+		return instrumentedActionWithStatusAndStop[T]{instrumented}
+	case withStatus:
+		return instrumentedActionWithStatus[T]{instrumented}
+	case withStop:
+		return instrumentedActionWithStop[T]{instrumented}
+	}
+	return instrumented
+}
+
+type instrumentedAction[T any] struct {
+	action action_kit_sdk.Action[T]
+	id     string
+}
+
+func (a instrumentedAction[T]) NewEmptyState() T {
+	return a.action.NewEmptyState()
+}
+
+func (a instrumentedAction[T]) Describe() action_kit_api.ActionDescription {
+	return a.action.Describe()
+}
+
+func (a instrumentedAction[T]) Prepare(ctx context.Context, state *T, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	ctx, end := a.begin(ctx, "prepare", attribute.String("steadybit.execution.id", request.ExecutionId.String()))
+	result, err := a.action.Prepare(ctx, state, request)
+	end(err)
+	return result, err
+}
+
+func (a instrumentedAction[T]) Start(ctx context.Context, state *T) (*action_kit_api.StartResult, error) {
+	ctx, end := a.begin(ctx, "start")
+	result, err := a.action.Start(ctx, state)
+	end(err)
+	return result, err
+}
+
+func (a instrumentedAction[T]) status(ctx context.Context, state *T) (*action_kit_api.StatusResult, error) {
+	ctx, end := a.begin(ctx, "status")
+	result, err := a.action.(action_kit_sdk.ActionWithStatus[T]).Status(ctx, state)
+	end(err)
+	return result, err
+}
+
+func (a instrumentedAction[T]) stop(ctx context.Context, state *T) (*action_kit_api.StopResult, error) {
+	ctx, end := a.begin(ctx, "stop")
+	result, err := a.action.(action_kit_sdk.ActionWithStop[T]).Stop(ctx, state)
+	end(err)
+	return result, err
+}
+
+// begin starts the span of a phase, the returned function ends it and counts its outcome.
+func (a instrumentedAction[T]) begin(ctx context.Context, phase string, attributes ...attribute.KeyValue) (context.Context, func(err error)) {
+	attributes = append(attributes, attribute.String("steadybit.action.id", a.id), attribute.String("steadybit.action.phase", phase))
+	ctx, span := exttracing.Start(ctx, a.id+" "+phase, attributes...)
+	return ctx, func(err error) {
+		exttracing.End(span, err)
+		observeActionPhase(a.id, phase, err)
+	}
+}
+
+type instrumentedActionWithStatus[T any] struct {
+	instrumentedAction[T]
+}
+
+func (a instrumentedActionWithStatus[T]) Status(ctx context.Context, state *T) (*action_kit_api.StatusResult, error) {
+	return a.status(ctx, state)
+}
+
+type instrumentedActionWithStop[T any] struct {
+	instrumentedAction[T]
+}
+
+func (a instrumentedActionWithStop[T]) Stop(ctx context.Context, state *T) (*action_kit_api.StopResult, error) {
+	return a.stop(ctx, state)
+}
+
+type instrumentedActionWithStatusAndStop[T any] struct {
+	instrumentedAction[T]
+}
+
+func (a instrumentedActionWithStatusAndStop[T]) Status(ctx context.Context, state *T) (*action_kit_api.StatusResult, error) {
+	return a.status(ctx, state)
+}
+
+func (a instrumentedActionWithStatusAndStop[T]) Stop(ctx context.Context, state *T) (*action_kit_api.StopResult, error) {
+	return a.stop(ctx, state)
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extmetrics
+
+import (
+	"context"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type fakeAction struct {
+	id       string
+	startErr error
+}
+
+func (a fakeAction) NewEmptyState() string {
+	return ""
+}
+
+func (a fakeAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{Id: a.id}
+}
+
+func (a fakeAction) Prepare(context.Context, *string, action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	return nil, nil
+}
+
+func (a fakeAction) Start(context.Context, *string) (*action_kit_api.StartResult, error) {
+	return nil, a.startErr
+}
+
+type fakeActionWithStop struct {
+	fakeAction
+}
+
+func (a fakeActionWithStop) Stop(context.Context, *string) (*action_kit_api.StopResult, error) {
+	return nil, nil
+}
+
+func TestInstrumentActionKeepsOptionalPhases(t *testing.T) {
+	// When
+	action := InstrumentAction[string](fakeActionWithStop{fakeAction{id: "with-stop"}})
+
+	// Then
+	_, withStop := action.(action_kit_sdk.ActionWithStop[string])
+	_, withStatus := action.(action_kit_sdk.ActionWithStatus[string])
+	assert.True(t, withStop)
+	assert.False(t, withStatus)
+	assert.Equal(t, "with-stop", action.Describe().Id)
+}
+
+func TestInstrumentActionCountsPhaseOutcomes(t *testing.T) {
+	// Given
+	action := InstrumentAction[string](fakeActionWithStop{fakeAction{id: "failing-start", startErr: errors.New("boom")}})
+	state := action.NewEmptyState()
+
+	// When
+	_, _ = action.Prepare(context.TODO(), &state, action_kit_api.PrepareActionRequestBody{})
+	_, _ = action.Start(context.TODO(), &state)
+	_, _ = action.(action_kit_sdk.ActionWithStop[string]).Stop(context.TODO(), &state)
+
+	// Then
+	assert.Equal(t, 1.0, testutil.ToFloat64(actionPhases.WithLabelValues("failing-start", "prepare", "success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(actionPhases.WithLabelValues("failing-start", "start", "failure")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(actionPhases.WithLabelValues("failing-start", "stop", "success")))
+}
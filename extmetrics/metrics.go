@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+// Package extmetrics collects metrics about the extension itself, exposed in the Prometheus format by
+// promhttp.Handler() along with the metrics of the Go runtime and the process.
+package extmetrics
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/steadybit/extension-kong/v2/exttracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"strconv"
+	"time"
+)
+
+var (
+	adminApiRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "kong_extension_admin_api_requests_total",
+		Help: "Requests sent to the admin API of Kong instances by status code, or error if no response was received.",
+	}, []string{"instance", "status"})
+	adminApiRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kong_extension_admin_api_request_duration_seconds",
+		Help:    "Latency of requests sent to the admin API of Kong instances.",
+		Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"instance"})
+	discoveryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kong_extension_discovery_duration_seconds",
+		Help:    "Duration of discovering all targets of a type.",
+		Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"type"})
+	discoveredTargets = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kong_extension_discovered_targets",
+		Help: "Number of targets found by the latest discovery of a type.",
+	}, []string{"type"})
+	discoveryFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "kong_extension_discovery_failures_total",
+		Help: "Failures to discover targets of a type within a Kong instance. Discovery continues with the other instances.",
+	}, []string{"type", "instance"})
+	actionPhases = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "kong_extension_action_phases_total",
+		Help: "Calls of the prepare, start, status and stop phases of actions by outcome, success or failure.",
+	}, []string{"action", "phase", "outcome"})
+	activeFaults = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "kong_extension_active_faults",
+		Help: "Number of faults the extension currently injects into Kong.",
+	})
+)
+
+// ObserveAdminApiRequest records a request to the admin API of an instance, status is 0 if no response was received.
+func ObserveAdminApiRequest(instance string, status int, duration time.Duration) {
+	label := "error"
+	if status > 0 {
+		label = strconv.Itoa(status)
+	}
+	adminApiRequests.WithLabelValues(instance, label).Inc()
+	adminApiRequestDuration.WithLabelValues(instance).Observe(duration.Seconds())
+}
+
+// BeginDiscovery starts tracing a discovery of all targets of a type, the returned function ends it and records its
+// duration and the number of targets found.
+func BeginDiscovery(ctx context.Context, targetType string) (context.Context, func(targets int)) {
+	ctx, span := exttracing.Start(ctx, "discover "+targetType, attribute.String("steadybit.discovery.type", targetType))
+	start := time.Now()
+	return ctx, func(targets int) {
+		discoveryDuration.WithLabelValues(targetType).Observe(time.Since(start).Seconds())
+		discoveredTargets.WithLabelValues(targetType).Set(float64(targets))
+		span.SetAttributes(attribute.Int("steadybit.discovery.targets", targets))
+		span.End()
+	}
+}
+
+// ObserveDiscoveryFailure records that targets of a type couldn't be discovered within an instance. The discovery
+// continues with other instances, so the failure is added to its span as an event instead of failing it.
+func ObserveDiscoveryFailure(ctx context.Context, targetType string, instance string, err error) {
+	discoveryFailures.WithLabelValues(targetType, instance).Inc()
+	trace.SpanFromContext(ctx).RecordError(err, trace.WithAttributes(attribute.String("kong.instance.name", instance)))
+}
+
+// AddActiveFaults records faults getting injected, or removed with a negative delta.
+func AddActiveFaults(delta int) {
+	activeFaults.Add(float64(delta))
+}
+
+func observeActionPhase(action string, phase string, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+	actionPhases.WithLabelValues(action, phase, outcome).Inc()
+}
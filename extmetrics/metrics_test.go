@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extmetrics
+
+import (
+	"context"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestObserveAdminApiRequestLabelsRequestsWithoutResponse(t *testing.T) {
+	// When
+	ObserveAdminApiRequest(t.Name(), 503, time.Millisecond)
+	ObserveAdminApiRequest(t.Name(), 0, time.Millisecond)
+
+	// Then
+	assert.Equal(t, 1.0, testutil.ToFloat64(adminApiRequests.WithLabelValues(t.Name(), "503")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(adminApiRequests.WithLabelValues(t.Name(), "error")))
+}
+
+func TestBeginDiscoveryRecordsTheTargetsFound(t *testing.T) {
+	// Given
+	_, end := BeginDiscovery(context.TODO(), t.Name())
+
+	// When
+	end(3)
+	ObserveDiscoveryFailure(context.TODO(), t.Name(), "kong", errors.New("boom"))
+
+	// Then
+	assert.Equal(t, 3.0, testutil.ToFloat64(discoveredTargets.WithLabelValues(t.Name())))
+	assert.Equal(t, 1.0, testutil.ToFloat64(discoveryFailures.WithLabelValues(t.Name(), "kong")))
+}
+
+func TestAddActiveFaults(t *testing.T) {
+	// Given
+	before := testutil.ToFloat64(activeFaults)
+
+	// When
+	AddActiveFaults(2)
+	AddActiveFaults(-1)
+
+	// Then
+	assert.Equal(t, before+1, testutil.ToFloat64(activeFaults))
+}
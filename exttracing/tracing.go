@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+// Package exttracing traces the actions and discoveries of the extension, down to the calls of Kong's admin API, with
+// OpenTelemetry. Spans are exported via OTLP once an endpoint is configured through the standard environment variables.
+package exttracing
+
+import (
+	"context"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extsignals"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+	"time"
+)
+
+const (
+	serviceName = "extension-kong"
+	tracerName  = "github.com/steadybit/extension-kong/v2"
+)
+
+// Init exports spans via OTLP over HTTP if OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set.
+// The exporter is configured by the standard OTEL_EXPORTER_OTLP_* variables, e.g., for headers or TLS. Without an
+// endpoint, spans are dropped at no cost.
+func Init(ctx context.Context) error {
+	if !isExportConfigured() {
+		return nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(extbuild.GetSemverVersionStringOrUnknown()),
+	))
+	if err != nil {
+		return err
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+
+	extsignals.AddSignalHandler(extsignals.SignalHandler{
+		Handler: func(_ os.Signal) {
+			// spans of actions stopped on shutdown are still exported
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := provider.Shutdown(ctx); err != nil {
+				log.Warn().Err(err).Msg("Failed to export the remaining spans")
+			}
+		},
+		Order: extsignals.OrderStopCustom,
+		Name:  "ShutdownTracing",
+	})
+	log.Info().Msg("Exporting OpenTelemetry traces via OTLP")
+	return nil
+}
+
+func isExportConfigured() bool {
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Start starts a span as child of the span within the context, if any.
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// End ends the span, marking it as failed if there is an error.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package exttracing
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"testing"
+)
+
+func TestInitWithoutEndpointKeepsSpansDropped(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+	require.NoError(t, Init(context.Background()))
+
+	_, span := Start(context.Background(), "test")
+	assert.False(t, span.SpanContext().IsValid())
+}
+
+func TestInitWithEndpointExportsSpans(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://127.0.0.1:4318")
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	require.NoError(t, Init(context.Background()))
+
+	assert.IsType(t, &sdktrace.TracerProvider{}, otel.GetTracerProvider())
+	_, span := Start(context.Background(), "test")
+	assert.True(t, span.SpanContext().IsValid())
+}
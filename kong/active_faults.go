@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"cmp"
+	"context"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/extension-kong/v2/config"
+	"github.com/steadybit/extension-kong/v2/extmetrics"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ActiveFault describes a fault the extension currently injects into Kong.
+type ActiveFault struct {
+	ExecutionId uuid.UUID `json:"executionId"`
+	Instance    string    `json:"instance"`
+	Workspace   string    `json:"workspace,omitempty"`
+	ServiceIds  []string  `json:"serviceIds,omitempty"`
+	RouteIds    []string  `json:"routeIds,omitempty"`
+	PluginIds   []string  `json:"pluginIds,omitempty"`
+	// KongPlugin and KubernetesObjects are set for instances managed by the Kong Ingress Controller.
+	KongPlugin        string                    `json:"kongPlugin,omitempty"`
+	KubernetesObjects []config.KubernetesObject `json:"kubernetesObjects,omitempty"`
+	StartedAt         time.Time                 `json:"startedAt"`
+	// ExpectedEnd is when the duration of the attack passes, the agent stops it at the latest then.
+	ExpectedEnd *time.Time `json:"expectedEnd,omitempty"`
+}
+
+type ActiveFaultList struct {
+	Faults []ActiveFault `json:"faults"`
+}
+
+type StopAllFaultsResult struct {
+	Stopped []uuid.UUID        `json:"stopped"`
+	Failed  []FaultStopFailure `json:"failed,omitempty"`
+}
+
+type FaultStopFailure struct {
+	ExecutionId uuid.UUID `json:"executionId"`
+	Error       string    `json:"error"`
+}
+
+type activeFault struct {
+	fault ActiveFault
+	state RequestTerminationState
+}
+
+// stopAllFaultsTimeout bounds how long StopAllFaults keeps removing faults.
+const stopAllFaultsTimeout = 2 * time.Minute
+
+// activeFaults holds the faults of all started attacks until they stop, keyed by execution ID. Only faults of this
+// extension process are known, other replicas track their own.
+var activeFaults sync.Map
+
+func registerActiveFault(state *RequestTerminationState, now time.Time) {
+	fault := ActiveFault{
+		ExecutionId:       state.ExecutionId,
+		Instance:          state.InstanceName,
+		Workspace:         state.Workspace,
+		KongPlugin:        state.KongPluginName,
+		KubernetesObjects: state.KubernetesObjects,
+		StartedAt:         now,
+	}
+	for _, plugin := range state.Plugins {
+		fault.PluginIds = append(fault.PluginIds, plugin.PluginId)
+		if !slices.Contains(fault.ServiceIds, plugin.ServiceId) {
+			fault.ServiceIds = append(fault.ServiceIds, plugin.ServiceId)
+		}
+		if plugin.RouteId != "" {
+			fault.RouteIds = append(fault.RouteIds, plugin.RouteId)
+		}
+	}
+	if state.Duration > 0 {
+		fault.ExpectedEnd = new(now.Add(state.Duration))
+	}
+	if _, loaded := activeFaults.Swap(state.ExecutionId, activeFault{fault: fault, state: *state}); !loaded {
+		extmetrics.AddActiveFaults(1)
+	}
+}
+
+func unregisterActiveFault(executionId uuid.UUID) {
+	if _, loaded := activeFaults.LoadAndDelete(executionId); loaded {
+		extmetrics.AddActiveFaults(-1)
+	}
+}
+
+// GetActiveFaults lists the faults currently injected, the longest running first.
+func GetActiveFaults() ActiveFaultList {
+	faults := make([]ActiveFault, 0)
+	activeFaults.Range(func(_, value any) bool {
+		faults = append(faults, value.(activeFault).fault)
+		return true
+	})
+	slices.SortFunc(faults, func(a, b ActiveFault) int {
+		return cmp.Or(a.StartedAt.Compare(b.StartedAt), cmp.Compare(a.ExecutionId.String(), b.ExecutionId.String()))
+	})
+	return ActiveFaultList{Faults: faults}
+}
+
+// StopAllFaults removes all active faults at once, e.g., during an incident. The agent still stops the attacks
+// afterward. Stopping is idempotent for all kinds of instances, plugins which are gone already count as removed, so
+// these stops succeed without changing anything. The faults are removed even if the caller gives up waiting, e.g.,
+// because the client of an HTTP request disconnects, until stopAllFaultsTimeout passes.
+func StopAllFaults(ctx context.Context) StopAllFaultsResult {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), stopAllFaultsTimeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	result := StopAllFaultsResult{Stopped: make([]uuid.UUID, 0)}
+	activeFaults.Range(func(_, value any) bool {
+		fault := value.(activeFault)
+		wg.Go(func() {
+			state := fault.state
+			_, err := RequestTerminationAction{}.Stop(ctx, &state)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to stop the fault of execution %s", fault.fault.ExecutionId)
+				result.Failed = append(result.Failed, FaultStopFailure{ExecutionId: fault.fault.ExecutionId, Error: err.Error()})
+				return
+			}
+			log.Warn().Msgf("Stopped the fault of execution %s on request", fault.fault.ExecutionId)
+			result.Stopped = append(result.Stopped, fault.fault.ExecutionId)
+		})
+		return true
+	})
+	wg.Wait()
+
+	slices.SortFunc(result.Stopped, func(a, b uuid.UUID) int { return cmp.Compare(a.String(), b.String()) })
+	slices.SortFunc(result.Failed, func(a, b FaultStopFailure) int {
+		return cmp.Compare(a.ExecutionId.String(), b.ExecutionId.String())
+	})
+	return result
+}
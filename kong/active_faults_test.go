@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-kit/extutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func startFakeFault(t *testing.T, serviceId string) *RequestTerminationState {
+	requestBody := extutil.JsonMangle(action_kit_api.PrepareActionRequestBody{
+		ExecutionId: uuid.New(),
+		Config: map[string]any{
+			"duration": 60000,
+			"status":   503,
+			"routeTag": "test",
+		},
+		Target: &action_kit_api.Target{
+			Attributes: map[string][]string{
+				"kong.instance.name": {"fake"},
+				"kong.service.id":    {serviceId},
+			},
+		},
+	})
+	action := NewServiceRequestTerminationAction()
+	state := action.NewEmptyState()
+	_, err := action.Prepare(context.TODO(), &state, requestBody)
+	require.NoError(t, err)
+	_, err = action.Start(context.TODO(), &state)
+	require.NoError(t, err)
+	return &state
+}
+
+func clearActiveFaults(t *testing.T) {
+	// faults of other tests which never stopped must not show up
+	activeFaults.Clear()
+	t.Cleanup(activeFaults.Clear)
+}
+
+func TestActiveFaultsListStartedAttacksUntilTheyStop(t *testing.T) {
+	// Given
+	clearActiveFaults(t)
+	newFakeKongAdmin(t, getFakeTaggedRoutes()...)
+	before := time.Now()
+	state := startFakeFault(t, "service")
+
+	// When
+	faults := GetActiveFaults().Faults
+
+	// Then
+	require.Len(t, faults, 1)
+	fault := faults[0]
+	assert.Equal(t, state.ExecutionId, fault.ExecutionId)
+	assert.Equal(t, "fake", fault.Instance)
+	assert.Equal(t, []string{"service"}, fault.ServiceIds)
+	assert.Equal(t, []string{"route-1", "route-2", "route-3"}, fault.RouteIds)
+	assert.Len(t, fault.PluginIds, 3)
+	assert.False(t, fault.StartedAt.Before(before))
+	require.NotNil(t, fault.ExpectedEnd)
+	assert.Equal(t, fault.StartedAt.Add(time.Minute), *fault.ExpectedEnd)
+
+	// When
+	_, err := NewServiceRequestTerminationAction().(action_kit_sdk.ActionWithStop[RequestTerminationState]).Stop(context.TODO(), state)
+
+	// Then
+	require.NoError(t, err)
+	assert.Empty(t, GetActiveFaults().Faults)
+}
+
+func TestStopAllFaultsRemovesAllPlugins(t *testing.T) {
+	// Given
+	clearActiveFaults(t)
+	fake := newFakeKongAdmin(t, getFakeTaggedRoutes()...)
+	first := startFakeFault(t, "service")
+	second := startFakeFault(t, "other-service")
+	require.Equal(t, 6, fake.enabledPlugins())
+
+	// When
+	result := StopAllFaults(context.TODO())
+
+	// Then
+	assert.ElementsMatch(t, []uuid.UUID{first.ExecutionId, second.ExecutionId}, result.Stopped)
+	assert.Empty(t, result.Failed)
+	assert.Equal(t, 0, fake.pluginCount())
+	assert.Empty(t, GetActiveFaults().Faults)
+
+	// When the agent stops the attack afterward
+	_, err := NewServiceRequestTerminationAction().(action_kit_sdk.ActionWithStop[RequestTerminationState]).Stop(context.TODO(), first)
+
+	// Then
+	require.NoError(t, err)
+}
+
+func TestStopAllFaultsKeepsFaultsWhichFailedToStop(t *testing.T) {
+	// Given
+	clearActiveFaults(t)
+	fake := newFakeKongAdmin(t, getFakeTaggedRoutes()...)
+	state := startFakeFault(t, "service")
+	fake.failOn["delete"] = 1
+
+	// When
+	result := StopAllFaults(context.TODO())
+
+	// Then
+	assert.Empty(t, result.Stopped)
+	require.Len(t, result.Failed, 1)
+	assert.Equal(t, state.ExecutionId, result.Failed[0].ExecutionId)
+	assert.Contains(t, result.Failed[0].Error, "Failed to delete plugins within Kong")
+	assert.Len(t, GetActiveFaults().Faults, 1)
+}
+
+func TestStopAllFaultsOutlivesTheCaller(t *testing.T) {
+	// Given
+	clearActiveFaults(t)
+	fake := newFakeKongAdmin(t, getFakeTaggedRoutes()...)
+	state := startFakeFault(t, "service")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// When
+	result := StopAllFaults(ctx)
+
+	// Then
+	assert.Equal(t, []uuid.UUID{state.ExecutionId}, result.Stopped)
+	assert.Empty(t, result.Failed)
+	assert.Equal(t, 0, fake.pluginCount())
+}
+
+func TestStopAllFaultsOfDbLessKongLetsTheAgentStopAfterward(t *testing.T) {
+	// Given
+	clearActiveFaults(t)
+	fake := newFakeDbLessKong(t, getFakeTaggedRoutes()...)
+	action := NewServiceRequestTerminationAction().(action_kit_sdk.ActionWithStop[RequestTerminationState])
+	state, err := prepareFakeRouteTagState(t)
+	require.NoError(t, err)
+	_, err = action.Start(context.TODO(), state)
+	require.NoError(t, err)
+
+	// When
+	result := StopAllFaults(context.TODO())
+
+	// Then
+	assert.Equal(t, []uuid.UUID{state.ExecutionId}, result.Stopped)
+	assert.Equal(t, 2, fake.reloads)
+
+	// When the agent stops the attack afterward
+	_, err = action.Stop(context.TODO(), state)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, 2, fake.reloads)
+}
@@ -23,6 +23,12 @@ func (a *attributeDescriber) DescribeAttributes() []discovery_kit_api.AttributeD
 				One:   "Kong instance name",
 				Other: "Kong instance names",
 			},
+		}, {
+			Attribute: "kong.workspace.name",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong workspace name",
+				Other: "Kong workspace names",
+			},
 		}, {
 			Attribute: "kong.service.name",
 			Label: discovery_kit_api.PluralLabel{
@@ -90,6 +96,186 @@ func (a *attributeDescriber) DescribeAttributes() []discovery_kit_api.AttributeD
 				One:   "Kong route path",
 				Other: "Kong route paths",
 			},
+		}, {
+			Attribute: "kong.service.k8s.kind",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong service Kubernetes kind",
+				Other: "Kong service Kubernetes kinds",
+			},
+		}, {
+			Attribute: "kong.service.k8s.namespace",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong service Kubernetes namespace",
+				Other: "Kong service Kubernetes namespaces",
+			},
+		}, {
+			Attribute: "kong.service.k8s.name",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong service Kubernetes object name",
+				Other: "Kong service Kubernetes object names",
+			},
+		}, {
+			Attribute: "kong.route.k8s.kind",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong route Kubernetes kind",
+				Other: "Kong route Kubernetes kinds",
+			},
+		}, {
+			Attribute: "kong.route.k8s.namespace",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong route Kubernetes namespace",
+				Other: "Kong route Kubernetes namespaces",
+			},
+		}, {
+			Attribute: "kong.route.k8s.name",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong route Kubernetes object name",
+				Other: "Kong route Kubernetes object names",
+			},
+		}, {
+			Attribute: "kong.data-plane.id",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong data plane ID",
+				Other: "Kong data plane IDs",
+			},
+		}, {
+			Attribute: "kong.data-plane.hostname",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong data plane hostname",
+				Other: "Kong data plane hostnames",
+			},
+		}, {
+			Attribute: "kong.data-plane.ip",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong data plane IP",
+				Other: "Kong data plane IPs",
+			},
+		}, {
+			Attribute: "kong.data-plane.version",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong data plane version",
+				Other: "Kong data plane versions",
+			},
+		}, {
+			Attribute: "kong.data-plane.sync-status",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong data plane sync status",
+				Other: "Kong data plane sync status",
+			},
+		}, {
+			Attribute: "kong.data-plane.config-hash",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong data plane configuration hash",
+				Other: "Kong data plane configuration hashes",
+			},
+		}, {
+			Attribute: "kong.data-plane.last-seen",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong data plane last seen",
+				Other: "Kong data plane last seen",
+			},
+		}, {
+			Attribute: "kong.node.id",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong node ID",
+				Other: "Kong node IDs",
+			},
+		}, {
+			Attribute: "kong.node.hostname",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong node hostname",
+				Other: "Kong node hostnames",
+			},
+		}, {
+			Attribute: "kong.node.version",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong version",
+				Other: "Kong versions",
+			},
+		}, {
+			Attribute: "kong.node.edition",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong edition",
+				Other: "Kong editions",
+			},
+		}, {
+			Attribute: "kong.node.database",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong database",
+				Other: "Kong databases",
+			},
+		}, {
+			Attribute: "kong.node.database.reachable",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong database reachable",
+				Other: "Kong database reachable",
+			},
+		}, {
+			Attribute: "kong.node.role",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong node role",
+				Other: "Kong node roles",
+			},
+		}, {
+			Attribute: "kong.node.plugin.available",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong plugin available",
+				Other: "Kong plugins available",
+			},
+		}, {
+			Attribute: "kong.node.plugin.enabled",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong plugin enabled",
+				Other: "Kong plugins enabled",
+			},
+		}, {
+			Attribute: "kong.node.connections.active",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong active connections",
+				Other: "Kong active connections",
+			},
+		}, {
+			Attribute: "kong.node.connections.accepted",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong accepted connections",
+				Other: "Kong accepted connections",
+			},
+		}, {
+			Attribute: "kong.node.connections.handled",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong handled connections",
+				Other: "Kong handled connections",
+			},
+		}, {
+			Attribute: "kong.node.connections.reading",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong reading connections",
+				Other: "Kong reading connections",
+			},
+		}, {
+			Attribute: "kong.node.connections.writing",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong writing connections",
+				Other: "Kong writing connections",
+			},
+		}, {
+			Attribute: "kong.node.connections.waiting",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong waiting connections",
+				Other: "Kong waiting connections",
+			},
+		}, {
+			Attribute: "kong.node.requests.total",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong total requests",
+				Other: "Kong total requests",
+			},
+		}, {
+			Attribute: "kong.node.fault-type",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Kong supported fault type",
+				Other: "Kong supported fault types",
+			},
 		},
 	}
 }
@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kong/v2/config"
+	"github.com/steadybit/extension-kong/v2/extmetrics"
+	"time"
+)
+
+type dataPlaneDiscovery struct {
+}
+
+var (
+	_ discovery_kit_sdk.TargetDescriber = (*dataPlaneDiscovery)(nil)
+)
+
+func NewDataPlaneDiscovery() discovery_kit_sdk.TargetDiscovery {
+	discovery := &dataPlaneDiscovery{}
+	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
+		discovery_kit_sdk.WithRefreshTargetsNow(),
+		discovery_kit_sdk.WithRefreshTargetsInterval(context.Background(), 60*time.Second),
+		discovery_kit_sdk.WithRefreshTargetsTrigger(context.Background(), config.SubscribeInstanceChanges(), 5*time.Second),
+	)
+}
+
+func (*dataPlaneDiscovery) Describe() discovery_kit_api.DiscoveryDescription {
+	return discovery_kit_api.DiscoveryDescription{
+		Id: DataPlaneTargetId,
+		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
+			CallInterval: new("60s"),
+		},
+	}
+}
+
+func (*dataPlaneDiscovery) DescribeTarget() discovery_kit_api.TargetDescription {
+	return discovery_kit_api.TargetDescription{
+		Id:       DataPlaneTargetId,
+		Label:    discovery_kit_api.PluralLabel{One: "Kong data plane", Other: "Kong data planes"},
+		Category: new("API gateway"),
+		Version:  extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:     new(DataPlaneIcon),
+		Table: discovery_kit_api.Table{
+			Columns: []discovery_kit_api.Column{
+				{Attribute: "kong.data-plane.hostname"},
+				{Attribute: "kong.instance.name"},
+				{Attribute: "kong.data-plane.version"},
+				{Attribute: "kong.data-plane.sync-status"},
+				{Attribute: "kong.data-plane.last-seen"},
+			},
+			OrderBy: []discovery_kit_api.OrderBy{
+				{
+					Attribute: "kong.data-plane.hostname",
+					Direction: "ASC",
+				},
+			},
+		},
+	}
+}
+
+func (*dataPlaneDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	ctx, end := extmetrics.BeginDiscovery(ctx, DataPlaneTargetId)
+	var targets = make([]discovery_kit_api.Target, 0, 10)
+	for _, instance := range config.GetInstances() {
+		targets = append(targets, getDataPlaneTargets(ctx, &instance)...)
+	}
+	end(len(targets))
+	return targets, nil
+}
+
+// getDataPlaneTargets lists the connected data planes of instances which are the control plane of a hybrid deployment.
+func getDataPlaneTargets(ctx context.Context, instance *config.Instance) []discovery_kit_api.Target {
+	controlPlane, err := instance.IsControlPlane(ctx)
+	if err != nil {
+		log.Err(err).Msgf("Failed to get the role of Kong instance %s (%s)", instance.Name, instance.Origin())
+		extmetrics.ObserveDiscoveryFailure(ctx, DataPlaneTargetId, instance.Name, err)
+		return []discovery_kit_api.Target{}
+	}
+	if !controlPlane {
+		return []discovery_kit_api.Target{}
+	}
+
+	dataPlanes, err := instance.GetConnectedDataPlanes(ctx)
+	if err != nil {
+		log.Err(err).Msgf("Failed to get data planes from Kong instance %s (%s)", instance.Name, instance.Origin())
+		extmetrics.ObserveDiscoveryFailure(ctx, DataPlaneTargetId, instance.Name, err)
+		return []discovery_kit_api.Target{}
+	}
+
+	targets := make([]discovery_kit_api.Target, 0, len(dataPlanes))
+	for _, dataPlane := range dataPlanes {
+		attributes := map[string][]string{
+			"kong.instance.name":          {instance.Name},
+			"kong.data-plane.id":          {dataPlane.Id},
+			"kong.data-plane.hostname":    {dataPlane.Hostname},
+			"kong.data-plane.ip":          {dataPlane.Ip},
+			"kong.data-plane.version":     {dataPlane.Version},
+			"kong.data-plane.sync-status": {dataPlane.SyncStatus},
+			"kong.data-plane.config-hash": {dataPlane.ConfigHash},
+			"kong.data-plane.last-seen":   {dataPlane.LastSeenAt().UTC().Format(time.RFC3339)},
+			"steadybit.label":             {dataPlane.Hostname},
+		}
+		for key, value := range dataPlane.Labels {
+			attributes["kong.data-plane.label."+key] = []string{value}
+		}
+		targets = append(targets, discovery_kit_api.Target{
+			Id:         fmt.Sprintf("%s-%s", instance.Name, dataPlane.Id),
+			Label:      dataPlane.Hostname,
+			TargetType: DataPlaneTargetId,
+			Attributes: attributes,
+		})
+	}
+	return targets
+}
@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-kit/extutil"
+	"github.com/steadybit/extension-kong/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func syncedDataPlanes(enabled int) []config.DataPlane {
+	now := time.Now().Unix()
+	return []config.DataPlane{
+		{Id: "dp-1", Hostname: "kong-dp-1", Version: "3.9.0", SyncStatus: "normal", ConfigHash: fmt.Sprint(enabled), LastSeen: now},
+		{Id: "dp-2", Hostname: "kong-dp-2", Version: "3.9.0", SyncStatus: "normal", ConfigHash: fmt.Sprint(enabled), LastSeen: now},
+		{Id: "dp-gone", Hostname: "kong-dp-gone", Version: "3.8.0", SyncStatus: "normal", ConfigHash: "0", LastSeen: now - 3600},
+	}
+}
+
+func laggingDataPlanes(enabled int) []config.DataPlane {
+	dataPlanes := syncedDataPlanes(enabled)
+	dataPlanes[1].ConfigHash = "0"
+	return dataPlanes
+}
+
+func prepareFakeHybridState(t *testing.T, actionConfig map[string]any) (*RequestTerminationState, error) {
+	requestBody := extutil.JsonMangle(action_kit_api.PrepareActionRequestBody{
+		ExecutionId: uuid.New(),
+		Config:      actionConfig,
+		Target: &action_kit_api.Target{
+			Attributes: map[string][]string{
+				"kong.instance.name": {"fake"},
+				"kong.service.id":    {"service"},
+			},
+		},
+	})
+	action := NewServiceRequestTerminationAction()
+	state := action.NewEmptyState()
+	_, err := action.Prepare(context.TODO(), &state, requestBody)
+	return &state, err
+}
+
+func withFastDataPlaneSyncPolling(t *testing.T) {
+	previous := dataPlaneSyncPollInterval
+	dataPlaneSyncPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { dataPlaneSyncPollInterval = previous })
+}
+
+func TestDiscoverConnectedDataPlanes(t *testing.T) {
+	fake := newFakeKongAdmin(t)
+	fake.dataPlanes = syncedDataPlanes
+
+	targets := getDataPlaneTargets(context.Background(), &config.GetInstances()[0])
+
+	require.Len(t, targets, 2)
+	assert.Equal(t, "fake-dp-1", targets[0].Id)
+	assert.Equal(t, "kong-dp-1", targets[0].Label)
+	assert.Equal(t, []string{"3.9.0"}, targets[0].Attributes["kong.data-plane.version"])
+	assert.Equal(t, []string{"normal"}, targets[0].Attributes["kong.data-plane.sync-status"])
+	assert.NotEmpty(t, targets[0].Attributes["kong.data-plane.last-seen"])
+}
+
+func TestDiscoverNoDataPlanesOfTraditionalNodes(t *testing.T) {
+	newFakeKongAdmin(t)
+
+	targets := getDataPlaneTargets(context.Background(), &config.GetInstances()[0])
+
+	assert.Empty(t, targets)
+}
+
+func TestStartWaitsForDataPlanesToSync(t *testing.T) {
+	// Given
+	withFastDataPlaneSyncPolling(t)
+	fake := newFakeKongAdmin(t)
+	fake.dataPlanes = syncedDataPlanes
+	state, err := prepareFakeHybridState(t, map[string]any{"status": 503, "waitForDataPlanes": true})
+	require.NoError(t, err)
+
+	// When
+	_, err = NewServiceRequestTerminationAction().Start(context.TODO(), state)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, 1, fake.enabledPlugins())
+}
+
+func TestStartRollsBackWhenDataPlanesDontSyncInTime(t *testing.T) {
+	// Given
+	withFastDataPlaneSyncPolling(t)
+	fake := newFakeKongAdmin(t)
+	fake.dataPlanes = laggingDataPlanes
+	state, err := prepareFakeHybridState(t, map[string]any{"status": 503, "waitForDataPlanes": true, "dataPlaneSyncTimeout": 100})
+	require.NoError(t, err)
+
+	// When
+	_, err = NewServiceRequestTerminationAction().Start(context.TODO(), state)
+
+	// Then
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "data planes kong-dp-2 didn't apply the configuration within 100ms")
+	assert.Equal(t, 0, fake.enabledPlugins())
+}
+
+func TestStartIgnoresHashesReportedBeforeThePluginsGotApplied(t *testing.T) {
+	// Given
+	withFastDataPlaneSyncPolling(t)
+	fake := newFakeKongAdmin(t)
+	fake.dataPlanes = func(enabled int) []config.DataPlane {
+		// the data planes switched to a configuration nobody reported before, but did so before the plugins got applied
+		dataPlanes := syncedDataPlanes(enabled + 1)
+		for i := range dataPlanes[:2] {
+			dataPlanes[i].LastSeen = time.Now().Add(-10 * time.Second).Unix()
+		}
+		return dataPlanes
+	}
+	state, err := prepareFakeHybridState(t, map[string]any{"status": 503, "waitForDataPlanes": true, "dataPlaneSyncTimeout": 100})
+	require.NoError(t, err)
+
+	// When
+	_, err = NewServiceRequestTerminationAction().Start(context.TODO(), state)
+
+	// Then
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "data planes kong-dp-1, kong-dp-2 didn't apply the configuration within 100ms")
+	assert.Equal(t, 0, fake.enabledPlugins())
+}
+
+func TestPrepareRejectsWaitingForDataPlanesOfTraditionalNodes(t *testing.T) {
+	newFakeKongAdmin(t)
+
+	_, err := prepareFakeHybridState(t, map[string]any{"status": 503, "waitForDataPlanes": true})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "isn't the control plane of a hybrid deployment")
+}
@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/extension-kong/v2/config"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+const defaultDataPlaneSyncTimeout = 60 * time.Second
+
+// dataPlaneSyncPollInterval defines how often the data planes are checked while waiting for them to sync.
+var dataPlaneSyncPollInterval = 2 * time.Second
+
+// getDataPlaneConfigHashes returns the configuration hashes the connected data planes currently report.
+func getDataPlaneConfigHashes(ctx context.Context, instance *config.Instance) (map[string]bool, error) {
+	dataPlanes, err := instance.GetConnectedDataPlanes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make(map[string]bool, len(dataPlanes))
+	for _, dataPlane := range dataPlanes {
+		hashes[dataPlane.ConfigHash] = true
+	}
+	return hashes, nil
+}
+
+// waitForDataPlaneSync waits until all connected data planes report the same configuration hash, which none of them
+// reported before the plugins got changed and which they reported after the change got applied at appliedAt.
+func waitForDataPlaneSync(ctx context.Context, instance *config.Instance, previousHashes map[string]bool, appliedAt time.Time, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// pending keeps the data planes of the last successful check, as the timeout may hit while fetching them
+	var pending []string
+	for {
+		dataPlanes, err := instance.GetConnectedDataPlanes(ctx)
+		if err == nil {
+			pending = pendingDataPlanes(dataPlanes, previousHashes, appliedAt)
+			if len(pending) == 0 {
+				if len(dataPlanes) == 0 {
+					log.Warn().Msgf("No data plane is connected to Kong instance %s", instance.Name)
+				}
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if pending == nil {
+				return fmt.Errorf("failed to get the data planes within %s: %w", timeout, err)
+			}
+			return fmt.Errorf("data planes %s didn't apply the configuration within %s", strings.Join(pending, ", "), timeout)
+		case <-time.After(dataPlaneSyncPollInterval):
+		}
+	}
+}
+
+// pendingDataPlanes returns the hostnames of all data planes which don't report the new configuration yet. The new
+// configuration is the one most data planes report, among those not reported before the change. Only reports sent
+// after the change got applied count, a data plane which last reported before might still have been applying an
+// earlier change.
+func pendingDataPlanes(dataPlanes []config.DataPlane, previousHashes map[string]bool, appliedAt time.Time) []string {
+	counts := map[string]int{}
+	for _, dataPlane := range dataPlanes {
+		if reportedSince(dataPlane, appliedAt) && !previousHashes[dataPlane.ConfigHash] {
+			counts[dataPlane.ConfigHash]++
+		}
+	}
+	current := ""
+	for _, hash := range slices.Sorted(maps.Keys(counts)) {
+		if counts[hash] > counts[current] {
+			current = hash
+		}
+	}
+
+	var pending []string
+	for _, dataPlane := range dataPlanes {
+		if current == "" || dataPlane.ConfigHash != current || !reportedSince(dataPlane, appliedAt) {
+			pending = append(pending, dataPlane.Hostname)
+		}
+	}
+	return pending
+}
+
+// reportedSince tells whether the data plane reported its configuration hash at or after the given time. Kong records
+// the last report in seconds, so reports within the same second count as well.
+func reportedSince(dataPlane config.DataPlane, since time.Time) bool {
+	return dataPlane.LastSeen >= since.Unix()
+}
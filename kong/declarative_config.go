@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/extension-kong/v2/config"
+	"gopkg.in/yaml.v3"
+	"slices"
+)
+
+// maxDeclarativeConfigAttempts bounds how often injecting plugins is attempted while the configuration of a
+// DB-less instance keeps changing concurrently.
+const maxDeclarativeConfigAttempts = 3
+
+// injectDeclarativePlugins adds the plugins of the execution to the configuration of a DB-less instance. The
+// configuration is only replaced if it didn't change since it was read, otherwise injecting is attempted again. Only
+// the hash of the modified configuration is kept in the state besides the plugins, the configuration itself may hold
+// credentials and must not leave the extension.
+func injectDeclarativePlugins(ctx context.Context, instance *config.Instance, state *RequestTerminationState) error {
+	var lastErr error
+	for range maxDeclarativeConfigAttempts {
+		original, err := instance.GetDeclarativeConfig(ctx)
+		if err != nil {
+			return err
+		}
+
+		modified, err := addDeclarativePlugins(original.Content, declarativePlugins(state))
+		if err != nil {
+			return err
+		}
+
+		hash, err := instance.GetConfigurationHash(ctx)
+		if err != nil {
+			return err
+		}
+		if hash != original.Hash {
+			lastErr = errors.New("the declarative configuration changed concurrently")
+			continue
+		}
+
+		if err := instance.ReloadDeclarativeConfig(ctx, modified); err != nil {
+			return err
+		}
+
+		injectedHash, err := instance.GetConfigurationHash(ctx)
+		if err != nil {
+			// without the hash, stopping couldn't tell whether the plugins got injected
+			if restoreErr := instance.ReloadDeclarativeConfig(context.WithoutCancel(ctx), original.Content); restoreErr != nil {
+				log.Error().Err(restoreErr).Msgf("Failed to restore the declarative configuration of Kong instance %s", instance.Name)
+			}
+			return fmt.Errorf("failed to read the configuration hash after injecting plugins: %w", err)
+		}
+		state.ConfigurationHash = injectedHash
+		return nil
+	}
+	return lastErr
+}
+
+// removeDeclarativePlugins removes the plugins of the execution from the current configuration of a DB-less
+// instance, changes made to the configuration during the attack are kept. Plugins which are gone already, e.g.,
+// because of an emergency stop or a configuration pushed in the meantime, count as removed. The admin API doesn't
+// support conditional reloads, so the hash is checked right before the reload to keep the window for concurrent
+// changes as small as possible.
+func removeDeclarativePlugins(ctx context.Context, instance *config.Instance, state *RequestTerminationState) error {
+	if state.ConfigurationHash == "" {
+		// the plugins never got injected
+		return nil
+	}
+
+	ids := make([]string, 0, len(state.Plugins))
+	for _, plugin := range state.Plugins {
+		ids = append(ids, plugin.PluginId)
+	}
+	var lastErr error
+	for range maxDeclarativeConfigAttempts {
+		current, err := instance.GetDeclarativeConfig(ctx)
+		if err != nil {
+			return err
+		}
+
+		modified, removed, err := removeDeclarativePluginsById(current.Content, ids)
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			return nil
+		}
+		if current.Hash != state.ConfigurationHash {
+			log.Warn().Msgf("The declarative configuration of Kong instance %s changed during the attack, removing only the injected plugins", instance.Name)
+		}
+
+		hash, err := instance.GetConfigurationHash(ctx)
+		if err != nil {
+			return err
+		}
+		if hash != current.Hash {
+			lastErr = errors.New("the declarative configuration changed concurrently")
+			continue
+		}
+		return instance.ReloadDeclarativeConfig(ctx, modified)
+	}
+	return lastErr
+}
+
+// declarativePlugins describes the plugins of the execution as entities of a declarative configuration.
+func declarativePlugins(state *RequestTerminationState) []map[string]any {
+	plugins := make([]map[string]any, 0, len(state.Plugins))
+	for _, plugin := range state.Plugins {
+		entity := map[string]any{
+			"id":      plugin.PluginId,
+			"name":    requestTerminationPlugin,
+			"enabled": true,
+			"tags":    []string{createdBySteadybitTag},
+			"config":  state.PluginConfig,
+			"service": plugin.ServiceId,
+		}
+		if plugin.RouteId != "" {
+			entity["route"] = plugin.RouteId
+		}
+		if state.ConsumerId != "" {
+			entity["consumer"] = state.ConsumerId
+		}
+		plugins = append(plugins, entity)
+	}
+	return plugins
+}
+
+func addDeclarativePlugins(content []byte, plugins []map[string]any) ([]byte, error) {
+	declarative, err := parseDeclarativeConfig(content)
+	if err != nil {
+		return nil, err
+	}
+	existing, _ := declarative["plugins"].([]any)
+	for _, plugin := range plugins {
+		existing = append(existing, plugin)
+	}
+	declarative["plugins"] = existing
+	return json.Marshal(declarative)
+}
+
+// removeDeclarativePluginsById removes the plugins with the given IDs wherever they are nested, e.g., at top level
+// or below services, routes and consumers.
+func removeDeclarativePluginsById(content []byte, ids []string) ([]byte, int, error) {
+	declarative, err := parseDeclarativeConfig(content)
+	if err != nil {
+		return nil, 0, err
+	}
+	removed := removePlugins(declarative, ids)
+	modified, err := json.Marshal(declarative)
+	return modified, removed, err
+}
+
+func removePlugins(node any, ids []string) int {
+	removed := 0
+	switch value := node.(type) {
+	case map[string]any:
+		for key, child := range value {
+			if plugins, ok := child.([]any); ok && key == "plugins" {
+				kept := make([]any, 0, len(plugins))
+				for _, plugin := range plugins {
+					if entity, ok := plugin.(map[string]any); ok && slices.Contains(ids, fmt.Sprint(entity["id"])) {
+						removed++
+						continue
+					}
+					kept = append(kept, plugin)
+				}
+				value[key] = kept
+				child = kept
+			}
+			removed += removePlugins(child, ids)
+		}
+	case []any:
+		for _, child := range value {
+			removed += removePlugins(child, ids)
+		}
+	}
+	return removed
+}
+
+func parseDeclarativeConfig(content []byte) (map[string]any, error) {
+	var declarative map[string]any
+	if err := yaml.Unmarshal(content, &declarative); err != nil {
+		return nil, fmt.Errorf("failed to parse the declarative configuration: %w", err)
+	}
+	if declarative == nil {
+		declarative = map[string]any{}
+	}
+	return declarative, nil
+}
@@ -0,0 +1,211 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/kong/go-kong/kong"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-kong/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const fakeDeclarativeConfig = `_format_version: "3.0"
+services:
+  - id: service
+    name: service
+    url: http://backend
+    routes:
+      - id: route-1
+        name: route-1
+        paths: [/one]
+        plugins:
+          - name: rate-limiting
+            config:
+              minute: 10
+`
+
+// fakeDbLessKong serves the admin API of a DB-less Kong node, whose configuration can only be replaced as a whole.
+type fakeDbLessKong struct {
+	mu      sync.Mutex
+	routes  []*kong.Route
+	content string
+	reloads int
+}
+
+func newFakeDbLessKong(t *testing.T, routes ...*kong.Route) *fakeDbLessKong {
+	f := &fakeDbLessKong{routes: routes, content: fakeDeclarativeConfig}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+
+	config.SetInstances([]config.Instance{{Name: "fake", BaseUrl: server.URL}})
+	t.Cleanup(resetGlobalInstanceConfiguration)
+	return f
+}
+
+func (f *fakeDbLessKong) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/":
+		writeJSON(w, http.StatusOK, map[string]any{"configuration": map[string]any{"database": "off"}})
+	case r.Method == http.MethodGet && r.URL.Path == "/status":
+		writeJSON(w, http.StatusOK, map[string]any{"configuration_hash": f.hash()})
+	case r.Method == http.MethodGet && r.URL.Path == "/config":
+		writeJSON(w, http.StatusOK, map[string]string{"config": f.content})
+	case r.Method == http.MethodPost && r.URL.Path == "/config":
+		var body struct {
+			Config string `json:"config"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+		f.content = body.Config
+		f.reloads++
+		writeJSON(w, http.StatusCreated, map[string]any{})
+	case r.Method == http.MethodGet && len(segments) == 2 && segments[0] == "services":
+		writeJSON(w, http.StatusOK, &kong.Service{ID: new(segments[1]), Name: new(segments[1])})
+	case r.Method == http.MethodGet && len(segments) == 3 && segments[0] == "services" && segments[2] == "routes":
+		writeJSON(w, http.StatusOK, map[string]any{"data": f.routes})
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"message": "cannot modify entities when running without database"})
+	}
+}
+
+func (f *fakeDbLessKong) hash() string {
+	sum := md5.Sum([]byte(f.content))
+	return hex.EncodeToString(sum[:])
+}
+
+func (f *fakeDbLessKong) getContent() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.content
+}
+
+func (f *fakeDbLessKong) setContent(content string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.content = content
+}
+
+func TestRequestTerminationAgainstDbLessKongRemovesInjectedPlugins(t *testing.T) {
+	// Given
+	fake := newFakeDbLessKong(t, getFakeTaggedRoutes()...)
+	action := NewServiceRequestTerminationAction().(action_kit_sdk.ActionWithStop[RequestTerminationState])
+
+	// When
+	state, err := prepareFakeRouteTagState(t)
+	require.NoError(t, err)
+	assert.True(t, state.DbLess)
+	assert.Equal(t, 0, fake.reloads)
+	_, err = action.Start(context.TODO(), state)
+	require.NoError(t, err)
+
+	// Then
+	declarative, err := parseDeclarativeConfig([]byte(fake.getContent()))
+	require.NoError(t, err)
+	plugins := declarative["plugins"].([]any)
+	require.Len(t, plugins, 3)
+	plugin := plugins[0].(map[string]any)
+	assert.Equal(t, "request-termination", plugin["name"])
+	assert.Equal(t, "service", plugin["service"])
+	assert.Equal(t, "route-1", plugin["route"])
+	assert.Equal(t, []any{"created-by=steadybit"}, plugin["tags"])
+	assert.EqualValues(t, 503, plugin["config"].(map[string]any)["status_code"])
+
+	_, err = action.Stop(context.TODO(), state)
+	require.NoError(t, err)
+	assertDeclarativeConfig(t, fakeDeclarativeConfig, fake.getContent())
+	assert.Equal(t, 2, fake.reloads)
+}
+
+func assertDeclarativeConfig(t *testing.T, expected string, actual string) {
+	expectedConfig, err := parseDeclarativeConfig([]byte(expected))
+	require.NoError(t, err)
+	expectedConfig["plugins"] = []any{}
+	actualConfig, err := parseDeclarativeConfig([]byte(actual))
+	require.NoError(t, err)
+	assert.Equal(t, expectedConfig, actualConfig)
+}
+
+func TestRequestTerminationAgainstDbLessKongKeepsConcurrentChanges(t *testing.T) {
+	// Given
+	fake := newFakeDbLessKong(t, getFakeTaggedRoutes()...)
+	action := NewServiceRequestTerminationAction().(action_kit_sdk.ActionWithStop[RequestTerminationState])
+	state, err := prepareFakeRouteTagState(t)
+	require.NoError(t, err)
+	_, err = action.Start(context.TODO(), state)
+	require.NoError(t, err)
+	declarative, err := parseDeclarativeConfig([]byte(fake.getContent()))
+	require.NoError(t, err)
+	declarative["consumers"] = []any{map[string]any{"username": "added-concurrently"}}
+	changed, err := json.Marshal(declarative)
+	require.NoError(t, err)
+	fake.setContent(string(changed))
+
+	// When
+	_, err = action.Stop(context.TODO(), state)
+
+	// Then
+	require.NoError(t, err)
+	declarative, err = parseDeclarativeConfig([]byte(fake.getContent()))
+	require.NoError(t, err)
+	assert.Empty(t, declarative["plugins"])
+	assert.Equal(t, []any{map[string]any{"username": "added-concurrently"}}, declarative["consumers"])
+	assert.Equal(t, 2, fake.reloads)
+}
+
+func TestRequestTerminationAgainstDbLessKongStopsPluginsWhichAreGone(t *testing.T) {
+	// Given
+	fake := newFakeDbLessKong(t, getFakeTaggedRoutes()...)
+	action := NewServiceRequestTerminationAction().(action_kit_sdk.ActionWithStop[RequestTerminationState])
+	state, err := prepareFakeRouteTagState(t)
+	require.NoError(t, err)
+	_, err = action.Start(context.TODO(), state)
+	require.NoError(t, err)
+	// a new configuration got pushed during the attack
+	fake.setContent(fakeDeclarativeConfig)
+
+	// When
+	_, err = action.Stop(context.TODO(), state)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, fakeDeclarativeConfig, fake.getContent())
+	assert.Equal(t, 1, fake.reloads)
+
+	// When stopping again
+	_, err = action.Stop(context.TODO(), state)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, 1, fake.reloads)
+}
+
+func TestRequestTerminationAgainstDbLessKongStopsWithoutStart(t *testing.T) {
+	// Given
+	fake := newFakeDbLessKong(t, getFakeTaggedRoutes()...)
+	state, err := prepareFakeRouteTagState(t)
+	require.NoError(t, err)
+
+	// When
+	_, err = NewServiceRequestTerminationAction().(action_kit_sdk.ActionWithStop[RequestTerminationState]).Stop(context.TODO(), state)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, 0, fake.reloads)
+}
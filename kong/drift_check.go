@@ -0,0 +1,323 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/kong/go-kong/kong"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kong/v2/config"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// maxReportedDrifts limits the differences listed in the title of a failed check, the detail lists all of them.
+const maxReportedDrifts = 3
+
+type DriftCheckAction struct {
+}
+
+type DriftCheckState struct {
+	ExecutionId  uuid.UUID
+	InstanceName string
+	Workspace    string
+	ServiceId    string
+	// RouteId limits the check to a single route, otherwise the service and all its routes are checked.
+	RouteId string
+	// Snapshot holds the configuration of every checked entity by a readable key, taken when the check started.
+	Snapshot map[string]string
+}
+
+func NewDriftCheckAction() action_kit_sdk.Action[DriftCheckState] {
+	return DriftCheckAction{}
+}
+
+var _ action_kit_sdk.Action[DriftCheckState] = (*DriftCheckAction)(nil)
+var _ action_kit_sdk.ActionWithStop[DriftCheckState] = (*DriftCheckAction)(nil)
+
+func (f DriftCheckAction) NewEmptyState() DriftCheckState {
+	return DriftCheckState{}
+}
+
+func (f DriftCheckAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          "com.steadybit.extension_kong.routes.drift_check",
+		Label:       "Configuration Drift",
+		Description: "Verify that the configuration of a Kong route, including its plugins, is the same at the end as at the start of the check, apart from the plugins of the extension.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        new(RouteIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: RouteTargetID,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "route-id",
+					Description: new("Find route by id"),
+					Query:       "kong.route.id=\"\"",
+				},
+				{
+					Label:       "route-name",
+					Description: new("Find route by name"),
+					Query:       "kong.route.name=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Kong"),
+		TimeControl: action_kit_api.TimeControlExternal,
+		Kind:        action_kit_api.Check,
+		Parameters:  driftCheckParameters(),
+		Prepare:     action_kit_api.MutatingEndpointReference{},
+		Start:       action_kit_api.MutatingEndpointReference{},
+		Stop:        new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func driftCheckParameters() []action_kit_api.ActionParameter {
+	return []action_kit_api.ActionParameter{
+		{
+			Label:        "Duration",
+			Name:         "duration",
+			Description:  new("The configuration is compared once the duration passed, so it should span the whole experiment."),
+			Type:         action_kit_api.ActionParameterTypeDuration,
+			Advanced:     new(false),
+			Required:     new(true),
+			DefaultValue: new("60s"),
+		},
+	}
+}
+
+func (f DriftCheckAction) Prepare(ctx context.Context, state *DriftCheckState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	instanceName := findFirstValue(request.Target.Attributes, "kong.instance.name")
+	if instanceName == nil {
+		return nil, extension_kit.ToError("Missing target attribute 'kong.instance.name'", nil)
+	}
+
+	instance, err := config.FindInstanceByName(*instanceName)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to find a configured instance named '%s'", *instanceName), err)
+	}
+	pinnedInstance := *instance
+	workspace := ""
+	if requestedWorkspace := findFirstValue(request.Target.Attributes, "kong.workspace.name"); requestedWorkspace != nil && instance.UsesWorkspaces() {
+		workspace = *requestedWorkspace
+	}
+	instance = instance.InWorkspace(workspace)
+
+	requestedServiceId := findFirstValue(request.Target.Attributes, "kong.service.id")
+	if requestedServiceId == nil {
+		return nil, extension_kit.ToError("Missing target attribute 'kong.service.id' required.", nil)
+	}
+	service, err := instance.FindService(ctx, requestedServiceId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to find service '%s' within Kong", *requestedServiceId), err)
+	}
+
+	state.ExecutionId = request.ExecutionId
+	state.InstanceName = instance.Name
+	state.Workspace = workspace
+	state.ServiceId = *service.ID
+	if requestedRouteId := findFirstValue(request.Target.Attributes, "kong.route.id"); requestedRouteId != nil {
+		route, err := instance.FindRoute(ctx, service, requestedRouteId)
+		if err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to find route '%s' within Kong", *requestedRouteId), err)
+		}
+		state.RouteId = *route.ID
+	}
+	// keep checking this instance until the check stops, even if the instance configuration gets reloaded
+	config.PinInstance(request.ExecutionId, pinnedInstance)
+	return nil, nil
+}
+
+func (f DriftCheckAction) Start(ctx context.Context, state *DriftCheckState) (*action_kit_api.StartResult, error) {
+	instance, err := findDriftCheckInstance(state)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := snapshotConfiguration(ctx, instance, state.ServiceId, state.RouteId)
+	if err == nil && len(state.RouteId) > 0 && len(snapshot) == 0 {
+		err = fmt.Errorf("%w: route '%s' got deleted", config.ErrRouteNotFound, state.RouteId)
+	}
+	if err != nil {
+		return nil, extension_kit.ToError("Failed to snapshot the configuration of Kong", err)
+	}
+	state.Snapshot = snapshot
+	return nil, nil
+}
+
+func (f DriftCheckAction) Stop(ctx context.Context, state *DriftCheckState) (*action_kit_api.StopResult, error) {
+	defer config.UnpinInstance(state.ExecutionId)
+	if state.Snapshot == nil {
+		// the check never started
+		return nil, nil
+	}
+	instance, err := findDriftCheckInstance(state)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := snapshotConfiguration(ctx, instance, state.ServiceId, state.RouteId)
+	if err != nil {
+		return nil, extension_kit.ToError("Failed to snapshot the configuration of Kong", err)
+	}
+
+	drifts := diffSnapshots(state.Snapshot, snapshot)
+	if len(drifts) == 0 {
+		return nil, nil
+	}
+	title := fmt.Sprintf("The configuration of Kong drifted: %s", strings.Join(drifts[:min(len(drifts), maxReportedDrifts)], ", "))
+	if len(drifts) > maxReportedDrifts {
+		title += fmt.Sprintf(" and %d more", len(drifts)-maxReportedDrifts)
+	}
+	return &action_kit_api.StopResult{Error: &action_kit_api.ActionKitError{
+		Title:  title,
+		Detail: new(strings.Join(drifts, "\n")),
+		Status: new(action_kit_api.Failed),
+	}}, nil
+}
+
+func findDriftCheckInstance(state *DriftCheckState) (*config.Instance, error) {
+	instance, err := config.FindInstanceForExecution(state.ExecutionId, state.InstanceName)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to find a configured instance named '%s'", state.InstanceName), err)
+	}
+	return instance.InWorkspace(state.Workspace), nil
+}
+
+// snapshotConfiguration captures the route and its plugins, or the service, its plugins and all its routes with their
+// plugins if no route is given. Plugins of the extension are left out, they come and go with attacks.
+func snapshotConfiguration(ctx context.Context, instance *config.Instance, serviceId, routeId string) (map[string]string, error) {
+	snapshot := map[string]string{}
+	service, err := instance.FindService(ctx, &serviceId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service '%s': %w", serviceId, err)
+	}
+
+	var routes []*kong.Route
+	if len(routeId) > 0 {
+		route, err := instance.FindRoute(ctx, service, &routeId)
+		if errors.Is(err, config.ErrRouteNotFound) {
+			// the route got deleted, which shows as removed route and plugins
+			return snapshot, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get route '%s': %w", routeId, err)
+		}
+		routes = []*kong.Route{route}
+	} else {
+		if err := addSnapshotEntry(snapshot, entityKey("service", service.Name, service.ID), service); err != nil {
+			return nil, err
+		}
+		plugins, err := instance.GetPluginsForService(ctx, service.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the plugins of service '%s': %w", serviceId, err)
+		}
+		if err := addPluginSnapshotEntries(snapshot, plugins, true); err != nil {
+			return nil, err
+		}
+		routes, err = instance.GetAllRoutesForService(ctx, service.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the routes of service '%s': %w", serviceId, err)
+		}
+	}
+
+	for _, route := range routes {
+		plugins, err := instance.GetPluginsForRoute(ctx, route.ID)
+		if kong.IsNotFoundErr(err) {
+			// the route got deleted after the routes were listed
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the plugins of route '%s': %w", kong.StringValue(route.ID), err)
+		}
+		if err := addSnapshotEntry(snapshot, entityKey("route", route.Name, route.ID), route); err != nil {
+			return nil, err
+		}
+		if err := addPluginSnapshotEntries(snapshot, plugins, false); err != nil {
+			return nil, err
+		}
+	}
+	return snapshot, nil
+}
+
+// addPluginSnapshotEntries adds the plugins, skipping those of the extension. Plugins scoped to a service and one of
+// its routes at once are listed for both, they are only added with their route.
+func addPluginSnapshotEntries(snapshot map[string]string, plugins []*kong.Plugin, serviceOnly bool) error {
+	for _, plugin := range plugins {
+		if isExtensionPlugin(plugin) || (serviceOnly && plugin.Route != nil) {
+			continue
+		}
+		if err := addSnapshotEntry(snapshot, entityKey("plugin", plugin.Name, plugin.ID), plugin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isExtensionPlugin tells whether the plugin got created by an attack, either directly or, for instances managed by
+// the Kong Ingress Controller, from the KongPlugin of an attack.
+func isExtensionPlugin(plugin *kong.Plugin) bool {
+	for _, tag := range plugin.Tags {
+		if tag == nil {
+			continue
+		}
+		if *tag == createdBySteadybitTag || strings.HasPrefix(*tag, "k8s-name:"+kongPluginNamePrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func entityKey(kind string, name, id *string) string {
+	if name != nil && *name != kong.StringValue(id) {
+		return fmt.Sprintf("%s '%s' (%s)", kind, *name, kong.StringValue(id))
+	}
+	return fmt.Sprintf("%s %s", kind, kong.StringValue(id))
+}
+
+// addSnapshotEntry adds the entity as canonical JSON. Timestamps are left out, they change whenever an entity is
+// written, even if its configuration stays the same.
+func addSnapshotEntry(snapshot map[string]string, key string, entity any) error {
+	content, err := json.Marshal(entity)
+	if err != nil {
+		return err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return err
+	}
+	delete(fields, "created_at")
+	delete(fields, "updated_at")
+	// maps are marshalled with sorted keys, which makes the JSON comparable
+	canonical, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	snapshot[key] = string(canonical)
+	return nil
+}
+
+// diffSnapshots describes every entity which got added, removed or changed.
+func diffSnapshots(before, after map[string]string) []string {
+	var drifts []string
+	for _, key := range slices.Sorted(maps.Keys(before)) {
+		content, ok := after[key]
+		if !ok {
+			drifts = append(drifts, "removed "+key)
+		} else if content != before[key] {
+			drifts = append(drifts, "changed "+key)
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(after)) {
+		if _, ok := before[key]; !ok {
+			drifts = append(drifts, "added "+key)
+		}
+	}
+	return drifts
+}
@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-kong/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+// fakeKongConfiguration serves a service with a single route and the plugins of both, which tests change in between.
+type fakeKongConfiguration struct {
+	*fakeKongAdmin
+	service        map[string]any
+	route          map[string]any
+	servicePlugins []map[string]any
+	routePlugins   []map[string]any
+}
+
+func (f *fakeKongConfiguration) change(fn func(f *fakeKongConfiguration)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fn(f)
+}
+
+func newFakeKongConfiguration(t *testing.T) *fakeKongConfiguration {
+	f := &fakeKongConfiguration{
+		fakeKongAdmin: newFakeKongAdmin(t),
+		service:       map[string]any{"id": "products", "name": "products", "host": "products.internal", "port": 8080, "updated_at": 1},
+		route:         map[string]any{"id": "checkout", "name": "checkout", "paths": []string{"/checkout"}, "service": map[string]any{"id": "products"}, "updated_at": 1},
+		servicePlugins: []map[string]any{
+			{"id": "p-1", "name": "rate-limiting", "config": map[string]any{"minute": 100}, "service": map[string]any{"id": "products"}},
+		},
+		routePlugins: []map[string]any{
+			{"id": "p-2", "name": "cors", "config": map[string]any{"origins": []string{"*"}}, "route": map[string]any{"id": "checkout"}},
+		},
+	}
+	f.handle("GET /services/products", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, f.service)
+	})
+	f.handle("GET /services/products/routes", func(w http.ResponseWriter, _ *http.Request) {
+		routes := []map[string]any{}
+		if f.route != nil {
+			routes = append(routes, f.route)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"data": routes, "next": nil})
+	})
+	f.handle("GET /services/products/plugins", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"data": f.servicePlugins, "next": nil})
+	})
+	f.handle("GET /routes/checkout/plugins", func(w http.ResponseWriter, _ *http.Request) {
+		if f.route == nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"message": "Not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"data": f.routePlugins, "next": nil})
+	})
+	return f
+}
+
+func startDriftCheck(t *testing.T, attributes map[string][]string) *DriftCheckState {
+	attributes["kong.instance.name"] = []string{"fake"}
+	attributes["kong.service.id"] = []string{"products"}
+	state, err := prepareAction[DriftCheckState](DriftCheckAction{}, attributes, map[string]any{"duration": 60000})
+	require.NoError(t, err)
+	_, err = DriftCheckAction{}.Start(context.TODO(), state)
+	require.NoError(t, err)
+	return state
+}
+
+func TestDriftCheckSucceedsWithoutChanges(t *testing.T) {
+	// Given
+	fake := newFakeKongConfiguration(t)
+	state := startDriftCheck(t, map[string][]string{})
+
+	// When
+	fake.change(func(f *fakeKongConfiguration) {
+		// writing an entity without changing it only updates its timestamp
+		f.service["updated_at"] = 2
+		f.routePlugins = append(f.routePlugins, map[string]any{"id": "p-3", "name": "request-termination", "tags": []string{createdBySteadybitTag}, "route": map[string]any{"id": "checkout"}})
+		f.servicePlugins = append(f.servicePlugins, map[string]any{"id": "p-4", "name": "request-termination", "tags": []string{"k8s-name:" + kongPluginNamePrefix + "abc"}, "service": map[string]any{"id": "products"}})
+	})
+	result, err := DriftCheckAction{}.Stop(context.TODO(), state)
+
+	// Then
+	require.NoError(t, err)
+	assert.Nil(t, result)
+	assert.Len(t, state.Snapshot, 4)
+}
+
+func TestDriftCheckReportsChangedServicesRoutesAndPlugins(t *testing.T) {
+	// Given
+	fake := newFakeKongConfiguration(t)
+	state := startDriftCheck(t, map[string][]string{})
+
+	// When
+	fake.change(func(f *fakeKongConfiguration) {
+		f.service["port"] = 9090
+		f.route["paths"] = []string{"/checkout", "/cart"}
+		f.servicePlugins = nil
+		f.routePlugins = append(f.routePlugins, map[string]any{"id": "p-5", "name": "key-auth", "route": map[string]any{"id": "checkout"}})
+	})
+	result, err := DriftCheckAction{}.Stop(context.TODO(), state)
+
+	// Then
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.NotNil(t, result.Error)
+	assert.Equal(t, action_kit_api.Failed, *result.Error.Status)
+	assert.Equal(t, "The configuration of Kong drifted: removed plugin 'rate-limiting' (p-1), changed route checkout, changed service products and 1 more", result.Error.Title)
+	assert.Equal(t, "removed plugin 'rate-limiting' (p-1)\nchanged route checkout\nchanged service products\nadded plugin 'key-auth' (p-5)", *result.Error.Detail)
+}
+
+func TestDriftCheckOfRouteIgnoresTheService(t *testing.T) {
+	// Given
+	fake := newFakeKongConfiguration(t)
+	state := startDriftCheck(t, map[string][]string{"kong.route.id": {"checkout"}})
+	assert.Len(t, state.Snapshot, 2)
+
+	// When
+	fake.change(func(f *fakeKongConfiguration) {
+		f.service["port"] = 9090
+		f.routePlugins[0]["config"] = map[string]any{"origins": []string{"https://example.com"}}
+	})
+	result, err := DriftCheckAction{}.Stop(context.TODO(), state)
+
+	// Then
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, "The configuration of Kong drifted: changed plugin 'cors' (p-2)", result.Error.Title)
+}
+
+func TestDriftCheckReportsDeletedRoute(t *testing.T) {
+	// Given
+	fake := newFakeKongConfiguration(t)
+	state := startDriftCheck(t, map[string][]string{"kong.route.id": {"checkout"}})
+
+	// When
+	fake.change(func(f *fakeKongConfiguration) {
+		f.route = nil
+		f.routePlugins = nil
+	})
+	result, err := DriftCheckAction{}.Stop(context.TODO(), state)
+
+	// Then
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, "The configuration of Kong drifted: removed plugin 'cors' (p-2), removed route checkout", result.Error.Title)
+}
+
+func TestDriftCheckComparesThePinnedInstance(t *testing.T) {
+	// Given
+	newFakeKongConfiguration(t)
+	state := startDriftCheck(t, map[string][]string{})
+
+	// When the instance gets removed from the configuration
+	config.SetInstances([]config.Instance{})
+	result, err := DriftCheckAction{}.Stop(context.TODO(), state)
+
+	// Then
+	require.NoError(t, err)
+	assert.Nil(t, result)
+	_, err = config.FindInstanceForExecution(state.ExecutionId, "fake")
+	assert.Error(t, err)
+}
+
+func TestDriftCheckPrepareFailsForUnknownRoute(t *testing.T) {
+	// Given
+	newFakeKongConfiguration(t)
+
+	// When
+	_, err := prepareAction[DriftCheckState](DriftCheckAction{}, map[string][]string{
+		"kong.instance.name": {"fake"},
+		"kong.service.id":    {"products"},
+		"kong.route.id":      {"unknown"},
+	}, nil)
+
+	// Then
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Failed to find route 'unknown' within Kong")
+}
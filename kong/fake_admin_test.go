@@ -0,0 +1,235 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/kong/go-kong/kong"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-kit/extutil"
+	"github.com/steadybit/extension-kong/v2/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeKongAdmin is a minimal stand-in for the Kong admin API covering the calls of the request termination action.
+// Single operations can be configured to fail in order to simulate the admin API breaking down mid-way. Tests of
+// other actions add the endpoints they need by handle.
+type fakeKongAdmin struct {
+	mu sync.Mutex
+	// url is where the fake is served
+	url      string
+	handlers *http.ServeMux
+	routes   []*kong.Route
+	plugins  map[string]*kong.Plugin
+	calls    map[string]int
+	// failOn maps an operation (create, update, delete) to the call number which is answered with a 503
+	failOn map[string]int
+	// dataPlanes, if set, turns the fake into the control plane of a hybrid deployment whose data planes are
+	// derived from the number of enabled plugins
+	dataPlanes func(enabled int) []config.DataPlane
+	// availablePlugins, if set, are reported as available on the node
+	availablePlugins []string
+}
+
+func newFakeKongAdmin(t *testing.T, routes ...*kong.Route) *fakeKongAdmin {
+	f := &fakeKongAdmin{
+		handlers: http.NewServeMux(),
+		routes:   routes,
+		plugins:  map[string]*kong.Plugin{},
+		calls:    map[string]int{},
+		failOn:   map[string]int{},
+	}
+	withoutRetries(t)
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	f.url = server.URL
+
+	config.SetInstances([]config.Instance{{Name: "fake", BaseUrl: server.URL}})
+	t.Cleanup(resetGlobalInstanceConfiguration)
+	return f
+}
+
+// withoutRetries makes a single failed admin API call fail the operation, which the failOn expectations rely on.
+func withoutRetries(t *testing.T) {
+	previous := config.Config
+	config.Config.AdminApiRetryMaxAttempts = 1
+	config.Config.AdminApiRetryInitialBackoff = time.Millisecond
+	config.Config.AdminApiRetryMaxBackoff = time.Millisecond
+	config.Config.AdminApiRetryStatusCodes = []int{502, 503, 504}
+	t.Cleanup(func() { config.Config = previous })
+}
+
+// newFakeKonnect serves the fake admin API as core entities of a Konnect control plane.
+func newFakeKonnect(t *testing.T, routes ...*kong.Route) *fakeKongAdmin {
+	f := &fakeKongAdmin{
+		handlers: http.NewServeMux(),
+		routes:   routes,
+		plugins:  map[string]*kong.Plugin{},
+		calls:    map[string]int{},
+		failOn:   map[string]int{},
+	}
+	withoutRetries(t)
+	server := httptest.NewServer(http.StripPrefix("/v2/control-planes/cp-1/core-entities", f))
+	t.Cleanup(server.Close)
+	f.url = server.URL
+
+	config.SetInstances([]config.Instance{{
+		Name:    "fake",
+		Type:    config.InstanceTypeKonnect,
+		Konnect: config.Konnect{ControlPlaneId: "cp-1", Token: "kpat_token", ApiUrl: server.URL},
+	}})
+	t.Cleanup(resetGlobalInstanceConfiguration)
+	return f
+}
+
+// handle serves requests matching the pattern of an http.ServeMux, e.g., "GET /upstreams/up-1/health", by the
+// handler instead of the built-in endpoints. Handlers run while the fake is locked, tests change what they serve
+// while holding mu.
+func (f *fakeKongAdmin) handle(pattern string, handler http.HandlerFunc) {
+	f.handlers.HandleFunc(pattern, handler)
+}
+
+func (f *fakeKongAdmin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	operation := ""
+	switch {
+	case len(segments) >= 3 && segments[2] == "plugins" && (r.Method == http.MethodPost || r.Method == http.MethodPut):
+		operation = "create"
+	case len(segments) == 4 && segments[2] == "plugins" && r.Method == http.MethodPatch:
+		operation = "update"
+	case len(segments) == 4 && segments[2] == "plugins" && r.Method == http.MethodDelete:
+		operation = "delete"
+	}
+	if operation != "" {
+		f.calls[operation]++
+		if f.failOn[operation] == f.calls[operation] {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"message": "simulated failure"})
+			return
+		}
+	}
+	if handler, pattern := f.handlers.Handler(r); pattern != "" {
+		handler.ServeHTTP(w, r)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/":
+		role := "traditional"
+		if f.dataPlanes != nil {
+			role = config.ControlPlaneRole
+		}
+		root := map[string]any{"configuration": map[string]any{"database": "postgres", "role": role}}
+		if f.availablePlugins != nil {
+			available := map[string]any{}
+			for _, plugin := range f.availablePlugins {
+				available[plugin] = true
+			}
+			root["plugins"] = map[string]any{"available_on_server": available}
+		}
+		writeJSON(w, http.StatusOK, root)
+	case r.Method == http.MethodGet && r.URL.Path == "/clustering/data-planes" && f.dataPlanes != nil:
+		enabled := 0
+		for _, plugin := range f.plugins {
+			if plugin.Enabled != nil && *plugin.Enabled {
+				enabled++
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"data": f.dataPlanes(enabled)})
+	case r.Method == http.MethodGet && len(segments) == 2 && segments[0] == "services":
+		writeJSON(w, http.StatusOK, &kong.Service{ID: new(segments[1]), Name: new(segments[1])})
+	case r.Method == http.MethodGet && len(segments) == 3 && segments[0] == "services" && segments[2] == "routes":
+		writeJSON(w, http.StatusOK, map[string]any{"data": f.routes})
+	case r.Method == http.MethodGet && len(segments) == 2 && segments[0] == "consumers":
+		writeJSON(w, http.StatusOK, &kong.Consumer{ID: new(segments[1]), Username: new(segments[1])})
+	case operation == "create":
+		var plugin kong.Plugin
+		if err := json.NewDecoder(r.Body).Decode(&plugin); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+		if len(segments) == 4 {
+			plugin.ID = new(segments[3])
+		} else {
+			plugin.ID = new(fmt.Sprintf("plugin-%d", f.calls[operation]))
+		}
+		f.plugins[*plugin.ID] = &plugin
+		writeJSON(w, http.StatusCreated, &plugin)
+	case operation == "update":
+		plugin, ok := f.plugins[segments[3]]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not found"})
+			return
+		}
+		var update kong.Plugin
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+		if update.Enabled != nil {
+			plugin.Enabled = update.Enabled
+		}
+		writeJSON(w, http.StatusOK, plugin)
+	case operation == "delete":
+		delete(f.plugins, segments[3])
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not found"})
+	}
+}
+
+func (f *fakeKongAdmin) enabledPlugins() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	enabled := 0
+	for _, plugin := range f.plugins {
+		if plugin.Enabled != nil && *plugin.Enabled {
+			enabled++
+		}
+	}
+	return enabled
+}
+
+func (f *fakeKongAdmin) pluginCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.plugins)
+}
+
+// prepareAction prepares the action for a target with the given attributes.
+func prepareAction[T any](action action_kit_sdk.Action[T], attributes map[string][]string, actionConfig map[string]any) (*T, error) {
+	requestBody := extutil.JsonMangle(action_kit_api.PrepareActionRequestBody{
+		ExecutionId: uuid.New(),
+		Config:      actionConfig,
+		Target:      &action_kit_api.Target{Attributes: attributes},
+	})
+	state := action.NewEmptyState()
+	_, err := action.Prepare(context.TODO(), &state, requestBody)
+	return &state, err
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func getFakeTaggedRoutes() []*kong.Route {
+	return []*kong.Route{
+		{ID: new("route-1"), Name: new("route-1"), Tags: []*string{new("test")}},
+		{ID: new("route-2"), Name: new("route-2"), Tags: []*string{new("test")}},
+		{ID: new("route-3"), Name: new("route-3"), Tags: []*string{new("test")}},
+	}
+}
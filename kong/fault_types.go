@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"fmt"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kong/v2/config"
+	"maps"
+	"slices"
+)
+
+const requestTerminationPlugin = "request-termination"
+
+// createdBySteadybitTag marks the plugins the extension creates through the admin API or declarative configuration.
+const createdBySteadybitTag = "created-by=steadybit"
+
+// faultTypePlugins maps the fault types of the extension to the Kong plugin they are injected through.
+var faultTypePlugins = map[string]string{
+	"request-termination": requestTerminationPlugin,
+}
+
+// supportedFaultTypes returns the fault types whose plugin is available, all of them if the available plugins are
+// unknown.
+func supportedFaultTypes(availablePlugins []string) []string {
+	var faultTypes []string
+	for _, faultType := range slices.Sorted(maps.Keys(faultTypePlugins)) {
+		if availablePlugins == nil || slices.Contains(availablePlugins, faultTypePlugins[faultType]) {
+			faultTypes = append(faultTypes, faultType)
+		}
+	}
+	return faultTypes
+}
+
+// requirePlugin fails if the plugin isn't available on the instance, which would otherwise only surface once the
+// plugin gets created.
+func requirePlugin(ctx context.Context, instance *config.Instance, plugin string) error {
+	supported, err := instance.SupportsPlugin(ctx, plugin)
+	if err != nil {
+		return extension_kit.ToError(fmt.Sprintf("Failed to determine the plugins available on Kong instance '%s'", instance.Name), err)
+	}
+	if !supported {
+		return extension_kit.ToError(fmt.Sprintf("The %s plugin isn't available on Kong instance '%s'. Add it to the plugins setting of Kong, e.g., KONG_PLUGINS=bundled,%s, and restart Kong.", plugin, instance.Name, plugin), nil)
+	}
+	return nil
+}
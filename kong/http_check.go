@@ -0,0 +1,325 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extconversion"
+	"github.com/steadybit/extension-kong/v2/config"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultHttpCheckRequestTimeout = 5 * time.Second
+	maxHttpCheckRequestsPerSecond  = 100
+)
+
+type HttpCheckAction struct {
+}
+
+type HttpCheckState struct {
+	ExecutionId uuid.UUID
+	// RouteName labels the metrics of the check.
+	RouteName string
+	// Url points to the route's path on the proxy of Kong, requests carry the route's Host.
+	Url                 string
+	Host                string
+	Method              string
+	Duration            time.Duration
+	RequestsPerSecond   int
+	RequestTimeout      time.Duration
+	ExpectedStatusCodes string
+	// MaxLatency marks slower requests as failed, zero disables the limit.
+	MaxLatency  time.Duration
+	SuccessRate int
+	// TLS holds the TLS settings of the instance, which apply to its proxy the same as to its admin API.
+	TLS config.TLS
+}
+
+type HttpCheckConfig struct {
+	// Duration, RequestTimeout and MaxLatency are in milliseconds.
+	Duration            int
+	RequestsPerSecond   int
+	RequestTimeout      int
+	ExpectedStatusCodes string
+	MaxLatency          int
+	SuccessRate         int
+}
+
+func NewHttpCheckAction() action_kit_sdk.Action[HttpCheckState] {
+	return HttpCheckAction{}
+}
+
+var _ action_kit_sdk.Action[HttpCheckState] = (*HttpCheckAction)(nil)
+var _ action_kit_sdk.ActionWithStatus[HttpCheckState] = (*HttpCheckAction)(nil)
+var _ action_kit_sdk.ActionWithStop[HttpCheckState] = (*HttpCheckAction)(nil)
+
+func (f HttpCheckAction) NewEmptyState() HttpCheckState {
+	return HttpCheckState{}
+}
+
+func (f HttpCheckAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          "com.steadybit.extension_kong.routes.http_check",
+		Label:       "HTTP Check via Kong",
+		Description: "Send HTTP requests to a Kong route through the Kong proxy and check the status codes and latencies of the responses.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        new(RouteIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: RouteTargetID,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "route-id",
+					Description: new("Find route by id"),
+					Query:       "kong.route.id=\"\"",
+				},
+				{
+					Label:       "route-name",
+					Description: new("Find route by name"),
+					Query:       "kong.route.name=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Kong"),
+		TimeControl: action_kit_api.TimeControlInternal,
+		Kind:        action_kit_api.Check,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Label:        "Duration",
+				Name:         "duration",
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				Advanced:     new(false),
+				Required:     new(true),
+				DefaultValue: new("30s"),
+			},
+			{
+				Label:        "Requests per second",
+				Name:         "requestsPerSecond",
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				Advanced:     new(false),
+				Required:     new(true),
+				DefaultValue: new("1"),
+				MinValue:     new(1),
+				MaxValue:     new(maxHttpCheckRequestsPerSecond),
+			},
+			{
+				Label:        "Expected status codes",
+				Name:         "expectedStatusCodes",
+				Type:         action_kit_api.ActionParameterTypeString,
+				Description:  new("Comma-separated list of status codes and ranges considered successful, e.g., 200-299,304."),
+				Advanced:     new(false),
+				Required:     new(true),
+				DefaultValue: new("200-299"),
+			},
+			{
+				Label:       "Max latency",
+				Name:        "maxLatency",
+				Type:        action_kit_api.ActionParameterTypeDuration,
+				Description: new("Requests taking longer are considered failed. Leave empty to not limit the latency."),
+				Advanced:    new(false),
+				Required:    new(false),
+			},
+			{
+				Label:        "Success rate",
+				Name:         "successRate",
+				Type:         action_kit_api.ActionParameterTypePercentage,
+				Description:  new("The check fails if fewer requests succeed."),
+				Advanced:     new(false),
+				Required:     new(true),
+				DefaultValue: new("100"),
+				MinValue:     new(0),
+				MaxValue:     new(100),
+			},
+			{
+				Label:        "Request timeout",
+				Name:         "requestTimeout",
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				Advanced:     new(true),
+				DefaultValue: new("5s"),
+			},
+		},
+		Prepare: action_kit_api.MutatingEndpointReference{},
+		Start:   action_kit_api.MutatingEndpointReference{},
+		Status: new(action_kit_api.MutatingEndpointReferenceWithCallInterval{
+			CallInterval: new("1s"),
+		}),
+		Stop: new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (f HttpCheckAction) Prepare(_ context.Context, state *HttpCheckState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	instanceName := findFirstValue(request.Target.Attributes, "kong.instance.name")
+	if instanceName == nil {
+		return nil, extension_kit.ToError("Missing target attribute 'kong.instance.name'", nil)
+	}
+
+	instance, err := config.FindInstanceByName(*instanceName)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to find a configured instance named '%s'", *instanceName), err)
+	}
+	if len(instance.ProxyUrl) == 0 {
+		return nil, extension_kit.ToError(fmt.Sprintf("Kong instance '%s' has no proxy URL configured, which the check sends its requests to", instance.Name), nil)
+	}
+
+	var checkConfig HttpCheckConfig
+	if err := extconversion.Convert(request.Config, &checkConfig); err != nil {
+		return nil, extension_kit.ToError("Failed to unmarshal the config.", err)
+	}
+	if checkConfig.Duration <= 0 {
+		return nil, extension_kit.ToError("The duration of the check must be positive", nil)
+	}
+	if checkConfig.RequestsPerSecond < 1 || checkConfig.RequestsPerSecond > maxHttpCheckRequestsPerSecond {
+		return nil, extension_kit.ToError(fmt.Sprintf("The requests per second must be between 1 and %d", maxHttpCheckRequestsPerSecond), nil)
+	}
+	if _, err := parseStatusRanges(checkConfig.ExpectedStatusCodes); err != nil {
+		return nil, extension_kit.ToError("Failed to parse the expected status codes", err)
+	}
+
+	path := "/"
+	if paths := request.Target.Attributes["kong.route.path"]; len(paths) > 0 {
+		path = paths[0]
+	}
+	if strings.HasPrefix(path, "~") {
+		// Kong marks regex paths with a leading ~, there is no single request path to derive from them
+		return nil, extension_kit.ToError(fmt.Sprintf("The path '%s' of the route is a regular expression, the check can't derive a request path from it", path), nil)
+	}
+	host := ""
+	if hosts := request.Target.Attributes["kong.route.host"]; len(hosts) > 0 {
+		host = hosts[0]
+	}
+	if strings.Contains(host, "*") {
+		return nil, extension_kit.ToError(fmt.Sprintf("The host '%s' of the route is a wildcard, the check can't derive a request host from it", host), nil)
+	}
+	method := http.MethodGet
+	if methods := request.Target.Attributes["kong.route.method"]; len(methods) > 0 && !slices.Contains(methods, http.MethodGet) {
+		method = methods[0]
+	}
+
+	state.ExecutionId = request.ExecutionId
+	state.RouteName = routeLabel(request.Target.Attributes)
+	state.Url = strings.TrimSuffix(instance.ProxyUrl, "/") + path
+	state.TLS = instance.TLS
+	state.Host = host
+	state.Method = method
+	state.Duration = time.Duration(checkConfig.Duration) * time.Millisecond
+	state.RequestsPerSecond = checkConfig.RequestsPerSecond
+	state.RequestTimeout = defaultHttpCheckRequestTimeout
+	if checkConfig.RequestTimeout > 0 {
+		state.RequestTimeout = time.Duration(checkConfig.RequestTimeout) * time.Millisecond
+	}
+	state.ExpectedStatusCodes = checkConfig.ExpectedStatusCodes
+	state.MaxLatency = time.Duration(checkConfig.MaxLatency) * time.Millisecond
+	state.SuccessRate = checkConfig.SuccessRate
+	return nil, nil
+}
+
+func (f HttpCheckAction) Start(_ context.Context, state *HttpCheckState) (*action_kit_api.StartResult, error) {
+	check, err := startHttpCheck(state)
+	if err != nil {
+		return nil, extension_kit.ToError("Failed to start the HTTP check", err)
+	}
+	httpChecks.Store(state.ExecutionId, check)
+	return nil, nil
+}
+
+func (f HttpCheckAction) Status(_ context.Context, state *HttpCheckState) (*action_kit_api.StatusResult, error) {
+	value, ok := httpChecks.Load(state.ExecutionId)
+	if !ok {
+		return nil, extension_kit.ToError("The HTTP check isn't running anymore, the extension may have been restarted", nil)
+	}
+	check := value.(*httpCheck)
+
+	metrics := httpCheckMetrics(state, check, time.Now())
+	return &action_kit_api.StatusResult{
+		Completed: check.isDone(),
+		Metrics:   &metrics,
+	}, nil
+}
+
+func (f HttpCheckAction) Stop(_ context.Context, state *HttpCheckState) (*action_kit_api.StopResult, error) {
+	value, ok := httpChecks.LoadAndDelete(state.ExecutionId)
+	if !ok {
+		// already stopped
+		return nil, nil
+	}
+	check := value.(*httpCheck)
+	check.stop()
+
+	metrics := httpCheckMetrics(state, check, time.Now())
+	result := &action_kit_api.StopResult{Metrics: &metrics}
+
+	successRate, total := check.successRate()
+	summary := fmt.Sprintf("%.2f%% of %d requests to %s succeeded, expected at least %d%%.", successRate, total, state.RouteName, state.SuccessRate)
+	if total == 0 || successRate < float64(state.SuccessRate) {
+		result.Error = &action_kit_api.ActionKitError{
+			Title:  summary,
+			Status: new(action_kit_api.Failed),
+		}
+		return result, nil
+	}
+	result.Summary = &action_kit_api.Summary{Level: action_kit_api.SummaryLevelInfo, Text: summary}
+	return result, nil
+}
+
+// httpCheckMetrics reports the success rate and the status code distribution of the results not reported yet, along
+// with the latency percentiles of the whole run.
+func httpCheckMetrics(state *HttpCheckState, check *httpCheck, now time.Time) action_kit_api.Metrics {
+	results := check.drain()
+	if len(results) == 0 {
+		return action_kit_api.Metrics{}
+	}
+
+	metric := func(name string, value float64, labels map[string]string) action_kit_api.Metric {
+		labels["kong.route.name"] = state.RouteName
+		labels["url"] = state.Url
+		return action_kit_api.Metric{Name: new(name), Metric: labels, Timestamp: now, Value: value}
+	}
+
+	successful := 0
+	statuses := map[string]int{}
+	for _, result := range results {
+		if result.success {
+			successful++
+		}
+		status := "error"
+		if result.status != 0 {
+			status = strconv.Itoa(result.status)
+		}
+		statuses[status]++
+	}
+
+	metrics := action_kit_api.Metrics{
+		metric("kong_http_check_success_rate", float64(successful)*100/float64(len(results)), map[string]string{}),
+	}
+	for _, status := range slices.Sorted(maps.Keys(statuses)) {
+		metrics = append(metrics, metric("kong_http_check_responses", float64(statuses[status]), map[string]string{"status": status}))
+	}
+	percentiles := []float64{50, 95, 99}
+	for i, latency := range check.latencyPercentiles(percentiles...) {
+		percentile := percentiles[i]
+		metrics = append(metrics, metric("kong_http_check_latency_ms", float64(latency.Microseconds())/1000, map[string]string{"percentile": fmt.Sprintf("p%d", int(percentile))}))
+	}
+	return metrics
+}
+
+func routeLabel(attributes map[string][]string) string {
+	if name := findFirstValue(attributes, "kong.route.name"); name != nil {
+		return *name
+	}
+	if id := findFirstValue(attributes, "kong.route.id"); id != nil {
+		return *id
+	}
+	return ""
+}
@@ -0,0 +1,220 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// statusRange is an inclusive range of HTTP status codes.
+type statusRange struct {
+	from int
+	to   int
+}
+
+// parseStatusRanges parses a comma-separated list of status codes and ranges, e.g., "200-299,304".
+func parseStatusRanges(value string) ([]statusRange, error) {
+	var ranges []statusRange
+	for part := range strings.SplitSeq(value, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			continue
+		}
+		fromValue, toValue, isRange := strings.Cut(part, "-")
+		if !isRange {
+			toValue = fromValue
+		}
+		from, fromErr := strconv.Atoi(strings.TrimSpace(fromValue))
+		to, toErr := strconv.Atoi(strings.TrimSpace(toValue))
+		if fromErr != nil || toErr != nil || from < 100 || to > 599 || from > to {
+			return nil, fmt.Errorf("invalid status code or range '%s'", part)
+		}
+		ranges = append(ranges, statusRange{from: from, to: to})
+	}
+	if len(ranges) == 0 {
+		return nil, errors.New("no status code given")
+	}
+	return ranges, nil
+}
+
+func statusMatches(ranges []statusRange, status int) bool {
+	return slices.ContainsFunc(ranges, func(r statusRange) bool {
+		return status >= r.from && status <= r.to
+	})
+}
+
+// httpCheckResult is the outcome of a single request, a zero status marks requests which got no response.
+type httpCheckResult struct {
+	status  int
+	latency time.Duration
+	success bool
+}
+
+// httpCheck sends the requests of a running check in the background and collects their results.
+type httpCheck struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu sync.Mutex
+	// pending holds the results not reported as metrics yet
+	pending []httpCheckResult
+	// latencies holds the latencies of all results, the reported percentiles cover the whole run
+	latencies  []time.Duration
+	total      int
+	successful int
+}
+
+// httpChecks holds the running checks by execution ID.
+var httpChecks sync.Map
+
+// startHttpCheck sends requests according to the state until its duration passed or the check gets stopped.
+func startHttpCheck(state *HttpCheckState) (*httpCheck, error) {
+	expected, err := parseStatusRanges(state.ExpectedStatusCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := state.TLS.NewTransport()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), state.Duration)
+	check := &httpCheck{cancel: cancel, done: make(chan struct{})}
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   state.RequestTimeout,
+		// redirects are responses of the gateway as well, they must be checked instead of followed
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	go func() {
+		defer close(check.done)
+		defer transport.CloseIdleConnections()
+		var requests sync.WaitGroup
+		defer requests.Wait()
+
+		ticker := time.NewTicker(time.Second / time.Duration(state.RequestsPerSecond))
+		defer ticker.Stop()
+		for {
+			requests.Go(func() {
+				check.record(sendCheckRequest(ctx, client, state, expected))
+			})
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return check, nil
+}
+
+func sendCheckRequest(ctx context.Context, client *http.Client, state *HttpCheckState, expected []statusRange) *httpCheckResult {
+	request, err := http.NewRequestWithContext(ctx, state.Method, state.Url, nil)
+	if err != nil {
+		return &httpCheckResult{}
+	}
+	if len(state.Host) > 0 {
+		request.Host = state.Host
+	}
+
+	started := time.Now()
+	response, err := client.Do(request)
+	latency := time.Since(started)
+	if err != nil {
+		if ctx.Err() != nil {
+			// the check ended while the request was in flight, it doesn't tell anything about the gateway
+			return nil
+		}
+		return &httpCheckResult{latency: latency}
+	}
+	_, _ = io.Copy(io.Discard, response.Body)
+	_ = response.Body.Close()
+
+	success := statusMatches(expected, response.StatusCode) && (state.MaxLatency == 0 || latency <= state.MaxLatency)
+	return &httpCheckResult{status: response.StatusCode, latency: latency, success: success}
+}
+
+func (c *httpCheck) record(result *httpCheckResult) {
+	if result == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending = append(c.pending, *result)
+	c.latencies = append(c.latencies, result.latency)
+	c.total++
+	if result.success {
+		c.successful++
+	}
+}
+
+// drain returns the results not reported yet.
+func (c *httpCheck) drain() []httpCheckResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	results := c.pending
+	c.pending = nil
+	return results
+}
+
+func (c *httpCheck) isDone() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop cancels outstanding requests and waits for the check to end.
+func (c *httpCheck) stop() {
+	c.cancel()
+	<-c.done
+}
+
+// successRate returns the percentage of successful requests among all requests sent so far.
+func (c *httpCheck) successRate() (float64, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.total == 0 {
+		return 0, 0
+	}
+	return float64(c.successful) * 100 / float64(c.total), c.total
+}
+
+// latencyPercentiles returns the given percentiles of the latencies of all requests sent so far.
+func (c *httpCheck) latencyPercentiles(percentiles ...float64) []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	slices.Sort(c.latencies)
+	result := make([]time.Duration, 0, len(percentiles))
+	for _, percentile := range percentiles {
+		result = append(result, latencyPercentile(c.latencies, percentile))
+	}
+	return result
+}
+
+// latencyPercentile returns the latency below which the given percentile of the sorted latencies fall, using the
+// nearest-rank method.
+func latencyPercentile(latencies []time.Duration, percentile float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(percentile/100*float64(len(latencies)))) - 1
+	return latencies[max(rank, 0)]
+}
@@ -0,0 +1,228 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"encoding/pem"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-kong/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeKongProxy answers requests for the host and path of a route, recording the requests it received. The fake
+// admin API also serves as the proxy of the instance.
+type fakeKongProxy struct {
+	*fakeKongAdmin
+	requests []*http.Request
+	status   int
+}
+
+func newFakeKongProxy(t *testing.T, status int) *fakeKongProxy {
+	f := &fakeKongProxy{fakeKongAdmin: newFakeKongAdmin(t), status: status}
+	f.handle("products.example.com/products", func(w http.ResponseWriter, r *http.Request) {
+		f.requests = append(f.requests, r)
+		w.WriteHeader(f.status)
+	})
+
+	config.SetInstances([]config.Instance{{Name: "fake", BaseUrl: f.url, ProxyUrl: f.url + "/"}})
+	return f
+}
+
+func productsRouteAttributes() map[string][]string {
+	return map[string][]string{
+		"kong.instance.name": {"fake"},
+		"kong.route.name":    {"products"},
+		"kong.route.host":    {"products.example.com"},
+		"kong.route.path":    {"/products"},
+		"kong.route.method":  {"POST", "GET"},
+	}
+}
+
+func runHttpCheck(t *testing.T, state *HttpCheckState) (action_kit_api.Metrics, *action_kit_api.StopResult) {
+	action := HttpCheckAction{}
+	_, err := action.Start(context.TODO(), state)
+	require.NoError(t, err)
+
+	var metrics action_kit_api.Metrics
+	require.Eventually(t, func() bool {
+		status, err := action.Status(context.TODO(), state)
+		require.NoError(t, err)
+		metrics = append(metrics, *status.Metrics...)
+		return status.Completed
+	}, 5*time.Second, 50*time.Millisecond)
+
+	result, err := action.Stop(context.TODO(), state)
+	require.NoError(t, err)
+	metrics = append(metrics, *result.Metrics...)
+	return metrics, result
+}
+
+func TestHttpCheckSendsRequestsForTheRoute(t *testing.T) {
+	// Given
+	proxy := newFakeKongProxy(t, http.StatusOK)
+	state, err := prepareAction[HttpCheckState](HttpCheckAction{}, productsRouteAttributes(), map[string]any{
+		"duration":            200,
+		"requestsPerSecond":   50,
+		"expectedStatusCodes": "200-299",
+		"successRate":         100,
+	})
+	require.NoError(t, err)
+
+	// When
+	metrics, result := runHttpCheck(t, state)
+
+	// Then
+	assert.Nil(t, result.Error)
+	assert.Contains(t, result.Summary.Text, "100.00% of")
+	proxy.mu.Lock()
+	defer proxy.mu.Unlock()
+	require.NotEmpty(t, proxy.requests)
+	assert.Equal(t, http.MethodGet, proxy.requests[0].Method)
+
+	names := map[string]bool{}
+	for _, metric := range metrics {
+		names[*metric.Name] = true
+		assert.Equal(t, "products", metric.Metric["kong.route.name"])
+		if *metric.Name == "kong_http_check_responses" {
+			assert.Equal(t, "200", metric.Metric["status"])
+		}
+	}
+	assert.Equal(t, map[string]bool{"kong_http_check_success_rate": true, "kong_http_check_responses": true, "kong_http_check_latency_ms": true}, names)
+}
+
+func TestHttpCheckFailsBelowSuccessRate(t *testing.T) {
+	// Given
+	newFakeKongProxy(t, http.StatusServiceUnavailable)
+	state, err := prepareAction[HttpCheckState](HttpCheckAction{}, productsRouteAttributes(), map[string]any{
+		"duration":            100,
+		"requestsPerSecond":   50,
+		"expectedStatusCodes": "200-299,304",
+		"successRate":         50,
+	})
+	require.NoError(t, err)
+
+	// When
+	_, result := runHttpCheck(t, state)
+
+	// Then
+	require.NotNil(t, result.Error)
+	assert.Equal(t, action_kit_api.Failed, *result.Error.Status)
+	assert.Contains(t, result.Error.Title, "0.00% of")
+	assert.Contains(t, result.Error.Title, "expected at least 50%")
+}
+
+func TestHttpCheckFailsSlowRequests(t *testing.T) {
+	// Given
+	newFakeKongProxy(t, http.StatusOK)
+	state, err := prepareAction[HttpCheckState](HttpCheckAction{}, productsRouteAttributes(), map[string]any{
+		"duration":            100,
+		"requestsPerSecond":   50,
+		"expectedStatusCodes": "200",
+		"maxLatency":          100,
+		"successRate":         100,
+	})
+	require.NoError(t, err)
+	state.MaxLatency = time.Nanosecond
+
+	// When
+	_, result := runHttpCheck(t, state)
+
+	// Then
+	require.NotNil(t, result.Error)
+}
+
+func TestPrepareHttpCheckRejectsUnusableRoutes(t *testing.T) {
+	newFakeKongProxy(t, http.StatusOK)
+	checkConfig := map[string]any{"duration": 1000, "requestsPerSecond": 1, "expectedStatusCodes": "200-299", "successRate": 100}
+
+	attributes := productsRouteAttributes()
+	attributes["kong.route.path"] = []string{"~/products/\\d+$"}
+	_, err := prepareAction[HttpCheckState](HttpCheckAction{}, attributes, checkConfig)
+	assert.ErrorContains(t, err, "is a regular expression")
+
+	attributes = productsRouteAttributes()
+	attributes["kong.route.host"] = []string{"*.example.com"}
+	_, err = prepareAction[HttpCheckState](HttpCheckAction{}, attributes, checkConfig)
+	assert.ErrorContains(t, err, "is a wildcard")
+
+	_, err = prepareAction[HttpCheckState](HttpCheckAction{}, productsRouteAttributes(), map[string]any{"duration": 1000, "requestsPerSecond": 1, "expectedStatusCodes": "2xx"})
+	assert.ErrorContains(t, err, "Failed to parse the expected status codes")
+}
+
+func TestPrepareHttpCheckRequiresProxyUrl(t *testing.T) {
+	newFakeKongAdmin(t)
+
+	_, err := prepareAction[HttpCheckState](HttpCheckAction{}, productsRouteAttributes(), map[string]any{"duration": 1000, "requestsPerSecond": 1, "expectedStatusCodes": "200"})
+
+	assert.ErrorContains(t, err, "Kong instance 'fake' has no proxy URL configured")
+}
+
+func TestLatencyPercentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	assert.Equal(t, 50*time.Millisecond, latencyPercentile(latencies, 50))
+	assert.Equal(t, 95*time.Millisecond, latencyPercentile(latencies, 95))
+	assert.Equal(t, 99*time.Millisecond, latencyPercentile(latencies, 99))
+	assert.Equal(t, time.Duration(0), latencyPercentile(nil, 99))
+}
+
+func TestHttpCheckMetricsReportLatencyPercentilesOfTheWholeRun(t *testing.T) {
+	// Given
+	state := &HttpCheckState{RouteName: "products"}
+	check := &httpCheck{}
+	for i := 1; i <= 99; i++ {
+		check.record(&httpCheckResult{status: http.StatusOK, latency: time.Second, success: true})
+	}
+	httpCheckMetrics(state, check, time.Now())
+	check.record(&httpCheckResult{status: http.StatusOK, latency: time.Millisecond, success: true})
+
+	// When
+	metrics := httpCheckMetrics(state, check, time.Now())
+
+	// Then
+	latencies := map[string]float64{}
+	for _, metric := range metrics {
+		if *metric.Name == "kong_http_check_latency_ms" {
+			latencies[metric.Metric["percentile"]] = metric.Value
+		}
+	}
+	assert.Equal(t, map[string]float64{"p50": 1000, "p95": 1000, "p99": 1000}, latencies)
+}
+
+func TestHttpCheckUsesTheTlsSettingsOfTheInstance(t *testing.T) {
+	// Given
+	proxy := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(proxy.Close)
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: proxy.Certificate().Raw}), 0o600))
+
+	config.SetInstances([]config.Instance{{Name: "fake", BaseUrl: proxy.URL, ProxyUrl: proxy.URL, TLS: config.TLS{CaFile: caFile}}})
+	t.Cleanup(resetGlobalInstanceConfiguration)
+	state, err := prepareAction[HttpCheckState](HttpCheckAction{}, productsRouteAttributes(), map[string]any{
+		"duration":            100,
+		"requestsPerSecond":   50,
+		"expectedStatusCodes": "200",
+		"successRate":         100,
+	})
+	require.NoError(t, err)
+
+	// When
+	_, result := runHttpCheck(t, state)
+
+	// Then
+	assert.Nil(t, result.Error)
+}
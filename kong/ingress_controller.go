@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/kong/go-kong/kong"
+	"github.com/steadybit/extension-kong/v2/config"
+	"slices"
+	"strings"
+)
+
+// kubernetesObjectOf maps a Kong entity back to the Kubernetes object the Kong Ingress Controller generated it
+// from. The controller records the object through k8s-kind, k8s-namespace and k8s-name tags.
+func kubernetesObjectOf(tags []*string) *config.KubernetesObject {
+	var object config.KubernetesObject
+	for _, tag := range tags {
+		if tag == nil {
+			continue
+		}
+		key, value, _ := strings.Cut(*tag, ":")
+		switch key {
+		case "k8s-kind":
+			object.Kind = value
+		case "k8s-namespace":
+			object.Namespace = value
+		case "k8s-name":
+			object.Name = value
+		}
+	}
+	if object.Kind == "" || object.Namespace == "" || object.Name == "" {
+		return nil
+	}
+	return &object
+}
+
+// kubernetesObjectsOf returns the distinct Kubernetes objects the given routes, or the service for a nil route,
+// were generated from.
+func kubernetesObjectsOf(service *kong.Service, routes []*kong.Route) ([]config.KubernetesObject, error) {
+	var objects []config.KubernetesObject
+	for _, route := range routes {
+		var object *config.KubernetesObject
+		if route != nil {
+			object = kubernetesObjectOf(route.Tags)
+			if object == nil {
+				return nil, fmt.Errorf("route '%s' wasn't generated by the Kong Ingress Controller", kong.StringValue(route.ID))
+			}
+		} else {
+			object = kubernetesObjectOf(service.Tags)
+			if object == nil {
+				return nil, fmt.Errorf("service '%s' wasn't generated by the Kong Ingress Controller", kong.StringValue(service.ID))
+			}
+		}
+		if !slices.Contains(objects, *object) {
+			objects = append(objects, *object)
+		}
+	}
+	return objects, nil
+}
+
+// addKubernetesObjectAttributes describes the Kubernetes object a Kong entity was generated from, if any.
+func addKubernetesObjectAttributes(attributes map[string][]string, prefix string, tags []*string) {
+	if object := kubernetesObjectOf(tags); object != nil {
+		attributes[prefix+".k8s.kind"] = []string{object.Kind}
+		attributes[prefix+".k8s.namespace"] = []string{object.Namespace}
+		attributes[prefix+".k8s.name"] = []string{object.Name}
+	}
+}
+
+const kongPluginNamePrefix = "steadybit-request-termination-"
+
+// getKubernetesClient returns the client KongPlugin resources are managed with, tests replace it with a fake.
+var getKubernetesClient = config.GetKubernetesClient
+
+// kongPluginName names the KongPlugin resource of an execution, which is unique within every namespace.
+func kongPluginName(executionId uuid.UUID) string {
+	return kongPluginNamePrefix + executionId.String()
+}
+
+// attachKongPlugins creates the KongPlugin of the execution in the namespace of every object and adds it to the
+// objects' plugins annotation. Whatever fails leaves neither plugins nor annotations behind.
+func attachKongPlugins(ctx context.Context, state *RequestTerminationState) error {
+	client, err := getKubernetesClient()
+	if err != nil {
+		return err
+	}
+
+	var namespaces []string
+	for _, object := range state.KubernetesObjects {
+		if !slices.Contains(namespaces, object.Namespace) {
+			namespaces = append(namespaces, object.Namespace)
+		}
+	}
+
+	var attachErr error
+	for _, namespace := range namespaces {
+		if attachErr = client.CreateKongPlugin(ctx, namespace, state.KongPluginName, requestTerminationPlugin, state.PluginConfig); attachErr != nil {
+			attachErr = fmt.Errorf("failed to create KongPlugin %s/%s: %w", namespace, state.KongPluginName, attachErr)
+			break
+		}
+	}
+	if attachErr == nil {
+		for _, object := range state.KubernetesObjects {
+			if attachErr = client.AddPluginAnnotation(ctx, object, state.KongPluginName); attachErr != nil {
+				attachErr = fmt.Errorf("failed to annotate %s: %w", object, attachErr)
+				break
+			}
+		}
+	}
+	if attachErr != nil {
+		// don't leave the fault partially active, even if the request got cancelled
+		return errors.Join(attachErr, detachKongPlugins(context.WithoutCancel(ctx), state))
+	}
+	return nil
+}
+
+// detachKongPlugins removes the KongPlugin of the execution from all objects and deletes it, continuing past
+// failures. All failures are returned joined together.
+func detachKongPlugins(ctx context.Context, state *RequestTerminationState) error {
+	client, err := getKubernetesClient()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	var namespaces []string
+	for _, object := range state.KubernetesObjects {
+		if err := client.RemovePluginAnnotation(ctx, object, state.KongPluginName); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove the plugins annotation of %s: %w", object, err))
+		}
+		if !slices.Contains(namespaces, object.Namespace) {
+			namespaces = append(namespaces, object.Namespace)
+		}
+	}
+	for _, namespace := range namespaces {
+		if err := client.DeleteKongPlugin(ctx, namespace, state.KongPluginName); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete KongPlugin %s/%s: %w", namespace, state.KongPluginName, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,208 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/kong/go-kong/kong"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-kit/extutil"
+	"github.com/steadybit/extension-kong/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	"testing"
+)
+
+// fakeKubernetes holds the Kubernetes objects the KongPlugin of an attack gets attached to.
+type fakeKubernetes struct {
+	client *fake.FakeDynamicClient
+}
+
+// fakeKubernetesObject is an object the fake serves, together with its resource.
+type fakeKubernetesObject struct {
+	resource   schema.GroupVersionResource
+	apiVersion string
+	object     config.KubernetesObject
+}
+
+var (
+	checkoutIngress = fakeKubernetesObject{
+		resource:   schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+		apiVersion: "networking.k8s.io/v1",
+		object:     config.KubernetesObject{Kind: "Ingress", Namespace: "shop", Name: "checkout"},
+	}
+	cartHttpRoute = fakeKubernetesObject{
+		resource:   schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"},
+		apiVersion: "gateway.networking.k8s.io/v1",
+		object:     config.KubernetesObject{Kind: "HTTPRoute", Namespace: "shop", Name: "cart"},
+	}
+)
+
+func newFakeKubernetes(t *testing.T, objects ...fakeKubernetesObject) *fakeKubernetes {
+	var resources []runtime.Object
+	for _, object := range objects {
+		resources = append(resources, &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": object.apiVersion,
+			"kind":       object.object.Kind,
+			"metadata":   map[string]any{"name": object.object.Name, "namespace": object.object.Namespace},
+		}})
+	}
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		config.KongPluginResource: "KongPluginList",
+	}, resources...)
+
+	previous := getKubernetesClient
+	getKubernetesClient = func() (*config.KubernetesClient, error) {
+		return config.NewKubernetesClient(client), nil
+	}
+	t.Cleanup(func() { getKubernetesClient = previous })
+	return &fakeKubernetes{client: client}
+}
+
+func (f *fakeKubernetes) get(t *testing.T, object fakeKubernetesObject) *unstructured.Unstructured {
+	current, err := f.client.Resource(object.resource).Namespace(object.object.Namespace).Get(context.Background(), object.object.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	return current
+}
+
+func (f *fakeKubernetes) annotation(t *testing.T, object fakeKubernetesObject) (string, bool) {
+	value, ok := f.get(t, object).GetAnnotations()[config.PluginsAnnotation]
+	return value, ok
+}
+
+func (f *fakeKubernetes) setAnnotation(t *testing.T, object fakeKubernetesObject, value string) {
+	current := f.get(t, object)
+	current.SetAnnotations(map[string]string{config.PluginsAnnotation: value})
+	_, err := f.client.Resource(object.resource).Namespace(object.object.Namespace).Update(context.Background(), current, metav1.UpdateOptions{})
+	require.NoError(t, err)
+}
+
+func (f *fakeKubernetes) kongPluginCount(t *testing.T) int {
+	plugins, err := f.client.Resource(config.KongPluginResource).List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	return len(plugins.Items)
+}
+
+func getFakeIngressRoutes() []*kong.Route {
+	return []*kong.Route{
+		{ID: new("route-1"), Name: new("route-1"), Tags: []*string{new("test"), new("k8s-kind:Ingress"), new("k8s-namespace:shop"), new("k8s-name:checkout")}},
+		{ID: new("route-2"), Name: new("route-2"), Tags: []*string{new("test"), new("k8s-kind:Ingress"), new("k8s-namespace:shop"), new("k8s-name:checkout")}},
+		{ID: new("route-3"), Name: new("route-3"), Tags: []*string{new("test"), new("k8s-kind:HTTPRoute"), new("k8s-namespace:shop"), new("k8s-name:cart")}},
+	}
+}
+
+func withIngressController(t *testing.T) {
+	instance := config.GetInstances()[0]
+	instance.IngressController = true
+	config.SetInstances([]config.Instance{instance})
+}
+
+func TestRequestTerminationThroughKongIngressController(t *testing.T) {
+	// Given
+	fake := newFakeKongAdmin(t, getFakeIngressRoutes()...)
+	withIngressController(t)
+	kubernetes := newFakeKubernetes(t, checkoutIngress, cartHttpRoute)
+	kubernetes.setAnnotation(t, checkoutIngress, "existing")
+	action := NewServiceRequestTerminationAction().(action_kit_sdk.ActionWithStop[RequestTerminationState])
+
+	// When
+	state, err := prepareFakeRouteTagState(t)
+	require.NoError(t, err)
+	_, err = action.Start(context.TODO(), state)
+	require.NoError(t, err)
+
+	// Then
+	assert.Equal(t, 0, fake.pluginCount())
+	assert.Len(t, state.KubernetesObjects, 2)
+	assert.Equal(t, 1, kubernetes.kongPluginCount(t))
+	annotation, _ := kubernetes.annotation(t, checkoutIngress)
+	assert.Equal(t, "existing,"+state.KongPluginName, annotation)
+	annotation, _ = kubernetes.annotation(t, cartHttpRoute)
+	assert.Equal(t, state.KongPluginName, annotation)
+
+	_, err = action.Stop(context.TODO(), state)
+	require.NoError(t, err)
+	assert.Equal(t, 0, kubernetes.kongPluginCount(t))
+	annotation, _ = kubernetes.annotation(t, checkoutIngress)
+	assert.Equal(t, "existing", annotation)
+	_, annotated := kubernetes.annotation(t, cartHttpRoute)
+	assert.False(t, annotated)
+}
+
+func TestStartThroughKongIngressControllerRollsBackWhenAnnotatingFails(t *testing.T) {
+	// Given
+	newFakeKongAdmin(t, getFakeIngressRoutes()...)
+	withIngressController(t)
+	kubernetes := newFakeKubernetes(t, checkoutIngress)
+	state, err := prepareFakeRouteTagState(t)
+	require.NoError(t, err)
+
+	// When
+	_, err = NewServiceRequestTerminationAction().Start(context.TODO(), state)
+
+	// Then
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to annotate HTTPRoute shop/cart")
+	assert.Equal(t, 0, kubernetes.kongPluginCount(t))
+	_, annotated := kubernetes.annotation(t, checkoutIngress)
+	assert.False(t, annotated)
+}
+
+func TestPrepareThroughKongIngressControllerRequiresGeneratedRoutes(t *testing.T) {
+	// Given
+	newFakeKongAdmin(t, getFakeTaggedRoutes()...)
+	withIngressController(t)
+
+	// When
+	_, err := prepareFakeRouteTagState(t)
+
+	// Then
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Failed to find the Kubernetes objects to attach the plugin to")
+}
+
+func TestPrepareThroughKongIngressControllerRejectsConsumers(t *testing.T) {
+	// Given
+	newFakeKongAdmin(t, getFakeIngressRoutes()...)
+	withIngressController(t)
+	requestBody := extutil.JsonMangle(action_kit_api.PrepareActionRequestBody{
+		ExecutionId: uuid.New(),
+		Config:      map[string]any{"consumer": "consumer", "routeTag": "test"},
+		Target: &action_kit_api.Target{
+			Attributes: map[string][]string{
+				"kong.instance.name": {"fake"},
+				"kong.service.id":    {"service"},
+			},
+		},
+	})
+	action := NewServiceRequestTerminationAction()
+	state := action.NewEmptyState()
+
+	// When
+	_, err := action.Prepare(context.TODO(), &state, requestBody)
+
+	// Then
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Limiting requests to a consumer isn't supported")
+}
+
+func TestKubernetesObjectAttributes(t *testing.T) {
+	attributes := map[string][]string{}
+
+	addKubernetesObjectAttributes(attributes, "kong.route", getFakeIngressRoutes()[2].Tags)
+	addKubernetesObjectAttributes(attributes, "kong.service", []*string{new("k8s-kind:Service")})
+
+	assert.Equal(t, map[string][]string{
+		"kong.route.k8s.kind":      {"HTTPRoute"},
+		"kong.route.k8s.namespace": {"shop"},
+		"kong.route.k8s.name":      {"cart"},
+	}, attributes)
+}
@@ -25,6 +25,12 @@ func TestWithTestContainers(t *testing.T) {
 		}, {
 			Name: "prepare with a route",
 			Test: testPrepareWithRoute,
+		}, {
+			Name: "prepare with a route tag creates a plugin per route",
+			Test: testPrepareWithRouteTag,
+		}, {
+			Name: "prepare fails when no route carries the tag",
+			Test: testPrepareFailsWhenNoRouteIsTagged,
 		}, {
 			Name: "start enables plugins",
 			Test: testStartEnablesPlugin,
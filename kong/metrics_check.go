@@ -0,0 +1,334 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extconversion"
+	"github.com/steadybit/extension-kong/v2/config"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const prometheusPlugin = "prometheus"
+
+// maxConsecutiveScrapeFailures bounds how many scrapes in a row may fail, e.g., during a rolling restart of Kong,
+// before the check fails.
+const maxConsecutiveScrapeFailures = 3
+
+type MetricsCheckAction struct {
+}
+
+type MetricsCheckState struct {
+	ExecutionId  uuid.UUID
+	InstanceName string
+	// Workspace and ServiceName select the samples of the targeted service, Kong labels them by name.
+	Workspace   string
+	ServiceName string
+	Duration    time.Duration
+	// End is when the check stops reporting, set once it started.
+	End time.Time
+	// Counters holds the values of the previous scrape by series, metrics report the increase since then.
+	Counters map[string]float64
+	// ScrapeFailures counts the scrapes which failed in a row.
+	ScrapeFailures int
+}
+
+type MetricsCheckConfig struct {
+	// Duration in milliseconds.
+	Duration int
+}
+
+func NewMetricsCheckAction() action_kit_sdk.Action[MetricsCheckState] {
+	return MetricsCheckAction{}
+}
+
+var _ action_kit_sdk.Action[MetricsCheckState] = (*MetricsCheckAction)(nil)
+var _ action_kit_sdk.ActionWithStatus[MetricsCheckState] = (*MetricsCheckAction)(nil)
+var _ action_kit_sdk.ActionWithStop[MetricsCheckState] = (*MetricsCheckAction)(nil)
+
+func (f MetricsCheckAction) NewEmptyState() MetricsCheckState {
+	return MetricsCheckState{}
+}
+
+func (f MetricsCheckAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          "com.steadybit.extension_kong.services.metrics",
+		Label:       "Kong Metrics",
+		Description: "Stream the request counts, status codes and upstream latencies Kong's prometheus plugin reports for a service and its routes.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        new(ServiceIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: ServiceTargetId,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "service-id",
+					Description: new("Find service by id"),
+					Query:       "kong.service.id=\"\"",
+				},
+				{
+					Label:       "service-name",
+					Description: new("Find service by name"),
+					Query:       "kong.service.name=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Kong"),
+		TimeControl: action_kit_api.TimeControlExternal,
+		Kind:        action_kit_api.Check,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Label:        "Duration",
+				Name:         "duration",
+				Description:  new("How long the metrics are reported."),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				Advanced:     new(false),
+				Required:     new(true),
+				DefaultValue: new("30s"),
+			},
+		},
+		Prepare: action_kit_api.MutatingEndpointReference{},
+		Start:   action_kit_api.MutatingEndpointReference{},
+		Status: new(action_kit_api.MutatingEndpointReferenceWithCallInterval{
+			CallInterval: new("5s"),
+		}),
+		Stop: new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (f MetricsCheckAction) Prepare(ctx context.Context, state *MetricsCheckState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	instanceName := findFirstValue(request.Target.Attributes, "kong.instance.name")
+	if instanceName == nil {
+		return nil, extension_kit.ToError("Missing target attribute 'kong.instance.name'", nil)
+	}
+	serviceName := findFirstValue(request.Target.Attributes, "kong.service.name")
+	if serviceName == nil {
+		return nil, extension_kit.ToError("Missing target attribute 'kong.service.name'", nil)
+	}
+
+	instance, err := config.FindInstanceByName(*instanceName)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to find a configured instance named '%s'", *instanceName), err)
+	}
+	if instance.IsKonnect() {
+		return nil, extension_kit.ToError(fmt.Sprintf("Kong instance '%s' is a Konnect control plane, which doesn't expose Prometheus metrics", instance.Name), nil)
+	}
+	if err := requirePlugin(ctx, instance, prometheusPlugin); err != nil {
+		return nil, err
+	}
+
+	var checkConfig MetricsCheckConfig
+	if err := extconversion.Convert(request.Config, &checkConfig); err != nil {
+		return nil, extension_kit.ToError("Failed to unmarshal the config.", err)
+	}
+	if checkConfig.Duration <= 0 {
+		return nil, extension_kit.ToError("The duration of the check must be positive", nil)
+	}
+
+	state.ExecutionId = request.ExecutionId
+	state.InstanceName = instance.Name
+	if workspace := findFirstValue(request.Target.Attributes, "kong.workspace.name"); workspace != nil && instance.UsesWorkspaces() {
+		state.Workspace = *workspace
+	}
+	state.ServiceName = *serviceName
+	state.Duration = time.Duration(checkConfig.Duration) * time.Millisecond
+	// keep scraping this instance until the check stops, even if the instance configuration gets reloaded
+	config.PinInstance(request.ExecutionId, *instance)
+	return nil, nil
+}
+
+func (f MetricsCheckAction) Start(ctx context.Context, state *MetricsCheckState) (*action_kit_api.StartResult, error) {
+	samples, err := scrapeServiceMetrics(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	state.Counters = countersOf(samples)
+	state.End = time.Now().Add(state.Duration)
+	return nil, nil
+}
+
+func (f MetricsCheckAction) Status(ctx context.Context, state *MetricsCheckState) (*action_kit_api.StatusResult, error) {
+	now := time.Now()
+	completed := !now.Before(state.End)
+	samples, err := scrapeServiceMetrics(ctx, state)
+	if err != nil {
+		state.ScrapeFailures++
+		if state.ScrapeFailures >= maxConsecutiveScrapeFailures {
+			return nil, err
+		}
+		// the increase since the last successful scrape gets reported with the next one
+		log.Warn().Err(err).Msgf("Failed to scrape the metrics of Kong instance %s, %d scrapes in a row failed", state.InstanceName, state.ScrapeFailures)
+		return &action_kit_api.StatusResult{Completed: completed}, nil
+	}
+	state.ScrapeFailures = 0
+	metrics := serviceMetrics(state, samples, now)
+	state.Counters = countersOf(samples)
+	return &action_kit_api.StatusResult{Completed: completed, Metrics: &metrics}, nil
+}
+
+func (f MetricsCheckAction) Stop(_ context.Context, state *MetricsCheckState) (*action_kit_api.StopResult, error) {
+	config.UnpinInstance(state.ExecutionId)
+	return nil, nil
+}
+
+// scrapeServiceMetrics returns the samples Kong reports for the targeted service.
+func scrapeServiceMetrics(ctx context.Context, state *MetricsCheckState) ([]config.MetricSample, error) {
+	instance, err := config.FindInstanceForExecution(state.ExecutionId, state.InstanceName)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to find a configured instance named '%s'", state.InstanceName), err)
+	}
+	samples, err := instance.GetMetrics(ctx)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to scrape the metrics of Kong instance '%s'", instance.Name), err)
+	}
+
+	var serviceSamples []config.MetricSample
+	for _, sample := range samples {
+		if sample.Labels["service"] != state.ServiceName {
+			continue
+		}
+		if workspace, ok := sample.Labels["workspace"]; ok && len(state.Workspace) > 0 && workspace != state.Workspace {
+			continue
+		}
+		switch sample.Name {
+		case "kong_http_requests_total", "kong_upstream_latency_ms_bucket", "kong_upstream_latency_ms_sum", "kong_upstream_latency_ms_count":
+			serviceSamples = append(serviceSamples, sample)
+		}
+	}
+	return serviceSamples, nil
+}
+
+func countersOf(samples []config.MetricSample) map[string]float64 {
+	counters := make(map[string]float64, len(samples))
+	for _, sample := range samples {
+		counters[sample.Key()] = sample.Value
+	}
+	return counters
+}
+
+// increase returns how much the counter of the sample grew since the previous scrape. Series which are new or went
+// down, e.g., after a restart of Kong, started counting within the interval.
+func increase(state *MetricsCheckState, sample config.MetricSample) float64 {
+	previous, ok := state.Counters[sample.Key()]
+	if !ok || sample.Value < previous {
+		return sample.Value
+	}
+	return sample.Value - previous
+}
+
+// routeLatency collects the increase of the upstream latency histogram of a route.
+type routeLatency struct {
+	sum     float64
+	count   float64
+	buckets map[float64]float64
+}
+
+// serviceMetrics reports the requests by route and status code, the error rate and the upstream latency of every
+// route since the previous scrape.
+func serviceMetrics(state *MetricsCheckState, samples []config.MetricSample, now time.Time) action_kit_api.Metrics {
+	metric := func(name string, value float64, route string, labels map[string]string) action_kit_api.Metric {
+		labels["kong.service.name"] = state.ServiceName
+		labels["kong.route.name"] = route
+		return action_kit_api.Metric{Name: new(name), Metric: labels, Timestamp: now, Value: value}
+	}
+
+	requests := map[string]map[string]float64{}
+	latencies := map[string]*routeLatency{}
+	for _, sample := range samples {
+		route := sample.Labels["route"]
+		delta := increase(state, sample)
+		switch sample.Name {
+		case "kong_http_requests_total":
+			if requests[route] == nil {
+				requests[route] = map[string]float64{}
+			}
+			requests[route][sample.Labels["code"]] += delta
+			continue
+		}
+
+		latency := latencies[route]
+		if latency == nil {
+			latency = &routeLatency{buckets: map[float64]float64{}}
+			latencies[route] = latency
+		}
+		switch sample.Name {
+		case "kong_upstream_latency_ms_sum":
+			latency.sum += delta
+		case "kong_upstream_latency_ms_count":
+			latency.count += delta
+		case "kong_upstream_latency_ms_bucket":
+			if le, err := strconv.ParseFloat(sample.Labels["le"], 64); err == nil {
+				latency.buckets[le] += delta
+			}
+		}
+	}
+
+	metrics := action_kit_api.Metrics{}
+	for _, route := range slices.Sorted(maps.Keys(requests)) {
+		total, serverErrors := 0.0, 0.0
+		for _, code := range slices.Sorted(maps.Keys(requests[route])) {
+			count := requests[route][code]
+			total += count
+			if len(code) == 3 && code[0] == '5' {
+				serverErrors += count
+			}
+			metrics = append(metrics, metric("kong_http_requests", count, route, map[string]string{"code": code}))
+		}
+		if total > 0 {
+			metrics = append(metrics, metric("kong_http_error_rate", serverErrors*100/total, route, map[string]string{}))
+		}
+	}
+	for _, route := range slices.Sorted(maps.Keys(latencies)) {
+		latency := latencies[route]
+		if latency.count == 0 {
+			continue
+		}
+		metrics = append(metrics,
+			metric("kong_upstream_latency_ms_avg", latency.sum/latency.count, route, map[string]string{}),
+			metric("kong_upstream_latency_ms_p95", bucketQuantile(0.95, latency.buckets), route, map[string]string{}),
+		)
+	}
+	return metrics
+}
+
+// bucketQuantile estimates a quantile from cumulative histogram buckets by their upper bound, interpolating linearly
+// within the bucket like Prometheus' histogram_quantile.
+func bucketQuantile(quantile float64, buckets map[float64]float64) float64 {
+	bounds := slices.Sorted(maps.Keys(buckets))
+	if len(bounds) == 0 {
+		return 0
+	}
+	total := buckets[bounds[len(bounds)-1]]
+	if total == 0 {
+		return 0
+	}
+	rank := quantile * total
+
+	lowerBound, lowerCount := 0.0, 0.0
+	for _, bound := range bounds {
+		count := buckets[bound]
+		if count >= rank {
+			if math.IsInf(bound, 1) {
+				// the quantile lies above the highest finite bucket
+				return lowerBound
+			}
+			if count == lowerCount {
+				return bound
+			}
+			return lowerBound + (bound-lowerBound)*(rank-lowerCount)/(count-lowerCount)
+		}
+		lowerBound, lowerCount = bound, count
+	}
+	return lowerBound
+}
@@ -0,0 +1,213 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"fmt"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-kong/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// fakeKongMetrics serves the metrics of Kong's prometheus plugin, reporting the given number of requests.
+type fakeKongMetrics struct {
+	*fakeKongAdmin
+	ok     int
+	failed int
+	// failScrapes answers the given number of scrapes with a 503
+	failScrapes int
+}
+
+func (f *fakeKongMetrics) serveMetrics(w http.ResponseWriter, _ *http.Request) {
+	if f.failScrapes > 0 {
+		f.failScrapes--
+		writeJSON(w, http.StatusServiceUnavailable, map[string]any{"message": "unavailable"})
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_, _ = fmt.Fprintf(w, `# TYPE kong_http_requests_total counter
+kong_http_requests_total{service="products",route="products-get",code="200",source="service",workspace="default",consumer=""} %[1]d
+kong_http_requests_total{service="products",route="products-get",code="500",source="kong",workspace="default",consumer=""} %[2]d
+kong_http_requests_total{service="orders",route="orders-get",code="200",source="service",workspace="default",consumer=""} 1000
+kong_upstream_latency_ms_bucket{service="products",route="products-get",workspace="default",le="25"} %[1]d
+kong_upstream_latency_ms_bucket{service="products",route="products-get",workspace="default",le="50"} %[1]d
+kong_upstream_latency_ms_bucket{service="products",route="products-get",workspace="default",le="+Inf"} %[1]d
+kong_upstream_latency_ms_sum{service="products",route="products-get",workspace="default"} %[3]d
+kong_upstream_latency_ms_count{service="products",route="products-get",workspace="default"} %[1]d
+`, f.ok, f.failed, f.ok*20)
+}
+
+func (f *fakeKongMetrics) failNextScrapes(count int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failScrapes = count
+}
+
+func (f *fakeKongMetrics) add(ok, failed int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ok += ok
+	f.failed += failed
+}
+
+func newFakeKongMetrics(t *testing.T, availablePlugins ...string) *fakeKongMetrics {
+	f := &fakeKongMetrics{fakeKongAdmin: newFakeKongAdmin(t), ok: 10, failed: 0}
+	f.availablePlugins = availablePlugins
+	f.handle("GET /metrics", f.serveMetrics)
+	return f
+}
+
+func prepareMetricsCheck() (*MetricsCheckState, error) {
+	return prepareAction[MetricsCheckState](MetricsCheckAction{}, map[string][]string{
+		"kong.instance.name": {"fake"},
+		"kong.service.name":  {"products"},
+	}, map[string]any{"duration": 30000})
+}
+
+func metricValues(metrics action_kit_api.Metrics) map[string]float64 {
+	values := map[string]float64{}
+	for _, metric := range metrics {
+		key := *metric.Name
+		if code, ok := metric.Metric["code"]; ok {
+			key += "/" + code
+		}
+		values[key] = metric.Value
+	}
+	return values
+}
+
+func TestMetricsCheckReportsIncreaseSincePreviousScrape(t *testing.T) {
+	// Given
+	fake := newFakeKongMetrics(t, "prometheus", "request-termination")
+	state, err := prepareMetricsCheck()
+	require.NoError(t, err)
+	_, err = MetricsCheckAction{}.Start(context.TODO(), state)
+	require.NoError(t, err)
+
+	// When
+	fake.add(30, 10)
+	result, err := MetricsCheckAction{}.Status(context.TODO(), state)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{
+		"kong_http_requests/200":       30,
+		"kong_http_requests/500":       10,
+		"kong_http_error_rate":         25,
+		"kong_upstream_latency_ms_avg": 20,
+		"kong_upstream_latency_ms_p95": 23.75,
+	}, metricValues(*result.Metrics))
+	for _, metric := range *result.Metrics {
+		assert.Equal(t, "products", metric.Metric["kong.service.name"])
+		assert.Equal(t, "products-get", metric.Metric["kong.route.name"])
+	}
+
+	// When nothing happened in between
+	result, err = MetricsCheckAction{}.Status(context.TODO(), state)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"kong_http_requests/200": 0, "kong_http_requests/500": 0}, metricValues(*result.Metrics))
+}
+
+func TestMetricsCheckToleratesTransientScrapeFailures(t *testing.T) {
+	// Given
+	fake := newFakeKongMetrics(t, "prometheus", "request-termination")
+	state, err := prepareMetricsCheck()
+	require.NoError(t, err)
+	_, err = MetricsCheckAction{}.Start(context.TODO(), state)
+	require.NoError(t, err)
+
+	// When a scrape fails
+	fake.add(30, 10)
+	fake.failNextScrapes(1)
+	result, err := MetricsCheckAction{}.Status(context.TODO(), state)
+
+	// Then
+	require.NoError(t, err)
+	assert.False(t, result.Completed)
+	assert.Nil(t, result.Metrics)
+
+	// When the next scrape succeeds, it reports the increase since the last successful one
+	result, err = MetricsCheckAction{}.Status(context.TODO(), state)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, 30.0, metricValues(*result.Metrics)["kong_http_requests/200"])
+
+	// When scrapes keep failing
+	fake.failNextScrapes(maxConsecutiveScrapeFailures)
+	for range maxConsecutiveScrapeFailures - 1 {
+		_, err = MetricsCheckAction{}.Status(context.TODO(), state)
+		require.NoError(t, err)
+	}
+	_, err = MetricsCheckAction{}.Status(context.TODO(), state)
+
+	// Then
+	assert.ErrorContains(t, err, "Failed to scrape the metrics of Kong instance 'fake'")
+}
+
+func TestMetricsCheckCompletesOnceTheDurationPassed(t *testing.T) {
+	// Given
+	newFakeKongMetrics(t, "prometheus", "request-termination")
+	state, err := prepareMetricsCheck()
+	require.NoError(t, err)
+	_, err = MetricsCheckAction{}.Start(context.TODO(), state)
+	require.NoError(t, err)
+	result, err := MetricsCheckAction{}.Status(context.TODO(), state)
+	require.NoError(t, err)
+	require.False(t, result.Completed)
+
+	// When
+	state.End = time.Now()
+	result, err = MetricsCheckAction{}.Status(context.TODO(), state)
+
+	// Then
+	require.NoError(t, err)
+	assert.True(t, result.Completed)
+	assert.NotNil(t, result.Metrics)
+}
+
+func TestMetricsCheckScrapesThePinnedInstance(t *testing.T) {
+	// Given
+	newFakeKongMetrics(t, "prometheus", "request-termination")
+	state, err := prepareMetricsCheck()
+	require.NoError(t, err)
+	_, err = MetricsCheckAction{}.Start(context.TODO(), state)
+	require.NoError(t, err)
+
+	// When the instance gets removed from the configuration
+	config.SetInstances([]config.Instance{})
+	_, err = MetricsCheckAction{}.Status(context.TODO(), state)
+
+	// Then
+	require.NoError(t, err)
+	_, err = MetricsCheckAction{}.Stop(context.TODO(), state)
+	require.NoError(t, err)
+	_, err = config.FindInstanceForExecution(state.ExecutionId, "fake")
+	assert.Error(t, err)
+}
+
+func TestPrepareMetricsCheckRequiresPrometheusPlugin(t *testing.T) {
+	newFakeKongMetrics(t, "request-termination")
+
+	_, err := prepareMetricsCheck()
+
+	assert.ErrorContains(t, err, "The prometheus plugin isn't available on Kong instance 'fake'")
+}
+
+func TestBucketQuantile(t *testing.T) {
+	buckets := map[float64]float64{10: 50, 100: 90, math.Inf(1): 100}
+
+	assert.Equal(t, 10.0, bucketQuantile(0.5, buckets))
+	assert.InDelta(t, 88.75, bucketQuantile(0.85, buckets), 0.001)
+	assert.Equal(t, 100.0, bucketQuantile(0.95, buckets))
+	assert.Equal(t, 0.0, bucketQuantile(0.95, map[float64]float64{}))
+}
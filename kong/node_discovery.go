@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kong/v2/config"
+	"github.com/steadybit/extension-kong/v2/extmetrics"
+	"strconv"
+	"time"
+)
+
+type nodeDiscovery struct {
+}
+
+var (
+	_ discovery_kit_sdk.TargetDescriber = (*nodeDiscovery)(nil)
+)
+
+func NewNodeDiscovery() discovery_kit_sdk.TargetDiscovery {
+	discovery := &nodeDiscovery{}
+	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
+		discovery_kit_sdk.WithRefreshTargetsNow(),
+		discovery_kit_sdk.WithRefreshTargetsInterval(context.Background(), 60*time.Second),
+		discovery_kit_sdk.WithRefreshTargetsTrigger(context.Background(), config.SubscribeInstanceChanges(), 5*time.Second),
+	)
+}
+
+func (*nodeDiscovery) Describe() discovery_kit_api.DiscoveryDescription {
+	return discovery_kit_api.DiscoveryDescription{
+		Id: NodeTargetId,
+		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
+			CallInterval: new("60s"),
+		},
+	}
+}
+
+func (*nodeDiscovery) DescribeTarget() discovery_kit_api.TargetDescription {
+	return discovery_kit_api.TargetDescription{
+		Id:       NodeTargetId,
+		Label:    discovery_kit_api.PluralLabel{One: "Kong node", Other: "Kong nodes"},
+		Category: new("API gateway"),
+		Version:  extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:     new(NodeIcon),
+		Table: discovery_kit_api.Table{
+			Columns: []discovery_kit_api.Column{
+				{Attribute: "kong.node.hostname"},
+				{Attribute: "kong.instance.name"},
+				{Attribute: "kong.node.version"},
+				{Attribute: "kong.node.edition"},
+				{Attribute: "kong.node.database"},
+				{Attribute: "kong.node.role"},
+			},
+			OrderBy: []discovery_kit_api.OrderBy{
+				{
+					Attribute: "kong.instance.name",
+					Direction: "ASC",
+				},
+			},
+		},
+	}
+}
+
+func (*nodeDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	ctx, end := extmetrics.BeginDiscovery(ctx, NodeTargetId)
+	var targets = make([]discovery_kit_api.Target, 0, 10)
+	for _, instance := range config.GetInstances() {
+		targets = append(targets, getNodeTargets(ctx, &instance)...)
+	}
+	end(len(targets))
+	return targets, nil
+}
+
+// getNodeTargets describes the node answering the admin API of the instance. Konnect control planes don't expose
+// their nodes.
+func getNodeTargets(ctx context.Context, instance *config.Instance) []discovery_kit_api.Target {
+	if instance.IsKonnect() {
+		return []discovery_kit_api.Target{}
+	}
+
+	info, err := instance.Probe(ctx)
+	if err != nil {
+		log.Err(err).Msgf("Failed to get the node information from Kong instance %s (%s)", instance.Name, instance.Origin())
+		extmetrics.ObserveDiscoveryFailure(ctx, NodeTargetId, instance.Name, err)
+		return []discovery_kit_api.Target{}
+	}
+
+	attributes := map[string][]string{
+		"kong.instance.name":             {instance.Name},
+		"kong.node.id":                   {info.NodeId},
+		"kong.node.hostname":             {info.Hostname},
+		"kong.node.version":              {info.Version},
+		"kong.node.edition":              {info.Edition},
+		"kong.node.database":             {info.Database},
+		"kong.node.database.reachable":   {strconv.FormatBool(info.DatabaseReachable)},
+		"kong.node.role":                 {info.Role},
+		"kong.node.connections.active":   {strconv.Itoa(info.Connections.Active)},
+		"kong.node.connections.accepted": {strconv.Itoa(info.Connections.Accepted)},
+		"kong.node.connections.handled":  {strconv.Itoa(info.Connections.Handled)},
+		"kong.node.connections.reading":  {strconv.Itoa(info.Connections.Reading)},
+		"kong.node.connections.writing":  {strconv.Itoa(info.Connections.Writing)},
+		"kong.node.connections.waiting":  {strconv.Itoa(info.Connections.Waiting)},
+		"kong.node.requests.total":       {strconv.Itoa(info.Connections.TotalRequests)},
+		"steadybit.label":                {info.Hostname},
+	}
+	if len(info.AvailablePlugins) > 0 {
+		attributes["kong.node.plugin.available"] = info.AvailablePlugins
+	}
+	if faultTypes := supportedFaultTypes(info.AvailablePlugins); len(faultTypes) > 0 {
+		attributes["kong.node.fault-type"] = faultTypes
+	}
+	if len(info.EnabledPlugins) > 0 {
+		attributes["kong.node.plugin.enabled"] = info.EnabledPlugins
+	}
+
+	return []discovery_kit_api.Target{{
+		Id:         fmt.Sprintf("%s-%s", instance.Name, info.NodeId),
+		Label:      info.Hostname,
+		TargetType: NodeTargetId,
+		Attributes: attributes,
+	}}
+}
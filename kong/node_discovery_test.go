@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"github.com/steadybit/extension-kong/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDiscoverNode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			writeJSON(w, http.StatusOK, map[string]any{
+				"version":  "3.4.3.5",
+				"hostname": "kong-7d9f",
+				"node_id":  "node-1",
+				"configuration": map[string]any{
+					"database": "postgres",
+					"role":     "traditional",
+				},
+				"plugins": map[string]any{
+					"available_on_server": map[string]any{
+						"request-termination": map[string]any{"version": "3.4.3", "priority": 2},
+						"cors":                true,
+					},
+					"enabled_in_cluster": []string{"rate-limiting"},
+				},
+			})
+		case "/status":
+			writeJSON(w, http.StatusOK, map[string]any{
+				"database": map[string]any{"reachable": true},
+				"server":   map[string]any{"connections_active": 7, "total_requests": 42},
+			})
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not found"})
+		}
+	}))
+	t.Cleanup(server.Close)
+	instance := &config.Instance{Name: "gateway", BaseUrl: server.URL}
+
+	targets := getNodeTargets(context.Background(), instance)
+
+	require.Len(t, targets, 1)
+	target := targets[0]
+	assert.Equal(t, "gateway-node-1", target.Id)
+	assert.Equal(t, "kong-7d9f", target.Label)
+	assert.Equal(t, []string{"3.4.3.5"}, target.Attributes["kong.node.version"])
+	assert.Equal(t, []string{config.EditionEnterprise}, target.Attributes["kong.node.edition"])
+	assert.Equal(t, []string{"postgres"}, target.Attributes["kong.node.database"])
+	assert.Equal(t, []string{"true"}, target.Attributes["kong.node.database.reachable"])
+	assert.Equal(t, []string{"cors", "request-termination"}, target.Attributes["kong.node.plugin.available"])
+	assert.Equal(t, []string{"rate-limiting"}, target.Attributes["kong.node.plugin.enabled"])
+	assert.Equal(t, []string{"request-termination"}, target.Attributes["kong.node.fault-type"])
+	assert.Equal(t, []string{"7"}, target.Attributes["kong.node.connections.active"])
+	assert.Equal(t, []string{"42"}, target.Attributes["kong.node.requests.total"])
+}
+
+func TestDiscoverNoNodesOfKonnect(t *testing.T) {
+	instance := &config.Instance{Name: "konnect", Type: config.InstanceTypeKonnect}
+
+	assert.Empty(t, getNodeTargets(context.Background(), instance))
+}
@@ -5,8 +5,11 @@ package kong
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/kong/go-kong/kong"
+	"github.com/rs/zerolog/log"
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
 	"github.com/steadybit/action-kit/go/action_kit_sdk"
 	extension_kit "github.com/steadybit/extension-kit"
@@ -14,25 +17,57 @@ import (
 	"github.com/steadybit/extension-kit/extconversion"
 	"github.com/steadybit/extension-kong/v2/config"
 	"github.com/steadybit/extension-kong/v2/utils"
+	"time"
 )
 
 type RequestTerminationAction struct {
 }
 
 type RequestTerminationState struct {
-	PluginIds    []string
+	ExecutionId  uuid.UUID
 	InstanceName string
-	ServiceId    string
-	RouteId      string
+	// Workspace is the Kong Enterprise workspace the plugins are created in, empty for instances without workspaces.
+	Workspace string
+	Plugins   []RequestTerminationPlugin
+	// DbLess marks executions against instances without database. Their plugins only exist within the declarative
+	// configuration while the attack is running.
+	DbLess       bool
+	PluginConfig kong.Configuration
+	ConsumerId   string
+	// ConfigurationHash is the hash a DB-less instance reported right after the plugins got injected.
+	ConfigurationHash string
+	// IngressController marks executions against instances managed by the Kong Ingress Controller. Their plugin is
+	// a KongPlugin resource attached to the Kubernetes objects the targeted Kong entities were generated from.
+	IngressController bool
+	KongPluginName    string
+	KubernetesObjects []config.KubernetesObject
+	// WaitForDataPlanes makes Start wait until all data planes of a hybrid deployment applied the plugins.
+	WaitForDataPlanes    bool
+	DataPlaneSyncTimeout time.Duration
+	// Duration of the attack, to tell when an active fault is expected to end.
+	Duration time.Duration
+}
+
+// RequestTerminationPlugin references a plugin created by the action together with the service or route it is scoped to.
+type RequestTerminationPlugin struct {
+	PluginId  string
+	ServiceId string
+	RouteId   string
 }
 
 type RequestTerminationConfig struct {
+	// Duration of the attack in milliseconds.
+	Duration    int
 	Consumer    string
 	Status      int
 	Body        string
 	Message     string
 	ContentType string
 	Trigger     string
+	RouteTag    string
+	// WaitForDataPlanes and DataPlaneSyncTimeout, in milliseconds, configure waiting for the data planes of hybrid deployments.
+	WaitForDataPlanes    bool
+	DataPlaneSyncTimeout int
 }
 
 func NewRequestTerminationAction() action_kit_sdk.Action[RequestTerminationState] {
@@ -125,6 +160,22 @@ func (f RequestTerminationAction) Describe() action_kit_api.ActionDescription {
 				Description: new("When not set, the plugin always activates. When set to a string, the plugin will activate exclusively on requests containing either a header or a query parameter that is named the string."),
 				Advanced:    new(true),
 			},
+			{
+				Label:        "Wait for data planes",
+				Name:         "waitForDataPlanes",
+				Type:         action_kit_api.ActionParameterTypeBoolean,
+				Description:  new("In hybrid deployments, wait until all connected data planes applied the plugin before reporting the attack as active."),
+				Advanced:     new(true),
+				DefaultValue: new("false"),
+			},
+			{
+				Label:        "Data plane sync timeout",
+				Name:         "dataPlaneSyncTimeout",
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				Description:  new("How long to wait for the data planes to apply the plugin."),
+				Advanced:     new(true),
+				DefaultValue: new("60s"),
+			},
 		},
 		Prepare: action_kit_api.MutatingEndpointReference{},
 		Start:   action_kit_api.MutatingEndpointReference{},
@@ -132,7 +183,7 @@ func (f RequestTerminationAction) Describe() action_kit_api.ActionDescription {
 	}
 }
 
-func (f RequestTerminationAction) Prepare(_ context.Context, state *RequestTerminationState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+func (f RequestTerminationAction) Prepare(ctx context.Context, state *RequestTerminationState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	instanceName := findFirstValue(request.Target.Attributes, "kong.instance.name")
 	if instanceName == nil {
 		return nil, extension_kit.ToError("Missing target attribute 'kong.instance.name'", nil)
@@ -142,6 +193,13 @@ func (f RequestTerminationAction) Prepare(_ context.Context, state *RequestTermi
 	if err != nil {
 		return nil, extension_kit.ToError(fmt.Sprintf("Failed to find a configured instance named '%s'", *instanceName), err)
 	}
+	// plugins are created in the workspace of the target, their scope must not leak into other workspaces
+	workspace := ""
+	if requestedWorkspace := findFirstValue(request.Target.Attributes, "kong.workspace.name"); requestedWorkspace != nil && instance.UsesWorkspaces() {
+		workspace = *requestedWorkspace
+	}
+	pinnedInstance := *instance
+	instance = instance.InWorkspace(workspace)
 
 	requestedServiceId := findFirstValue(request.Target.Attributes, "kong.service.id")
 	requestedRouteId := findFirstValue(request.Target.Attributes, "kong.route.id")
@@ -149,29 +207,49 @@ func (f RequestTerminationAction) Prepare(_ context.Context, state *RequestTermi
 		return nil, extension_kit.ToError("Missing target attribute 'kong.service.id' required.", nil)
 	}
 
-	service, err := instance.FindService(requestedServiceId)
+	service, err := instance.FindService(ctx, requestedServiceId)
 	if err != nil {
 		return nil, extension_kit.ToError(fmt.Sprintf("Failed to find service '%s' within Kong", *requestedServiceId), err)
 	}
 
+	if err := requirePlugin(ctx, instance, requestTerminationPlugin); err != nil {
+		return nil, err
+	}
+
 	var route *kong.Route
 	if requestedRouteId != nil {
-		route, err = instance.FindRoute(service, requestedRouteId)
+		route, err = instance.FindRoute(ctx, service, requestedRouteId)
 		if err != nil {
 			return nil, extension_kit.ToError(fmt.Sprintf("Failed to find route '%s' within Kong", *requestedRouteId), err)
 		}
 	}
 
-	var config RequestTerminationConfig
-	if err := extconversion.Convert(request.Config, &config); err != nil {
+	var terminationConfig RequestTerminationConfig
+	if err := extconversion.Convert(request.Config, &terminationConfig); err != nil {
 		return nil, extension_kit.ToError("Failed to unmarshal the config.", err)
 	}
+	state.Duration = time.Duration(terminationConfig.Duration) * time.Millisecond
+
+	if terminationConfig.WaitForDataPlanes {
+		controlPlane, err := instance.IsControlPlane(ctx)
+		if err != nil {
+			return nil, extension_kit.ToError("Failed to determine whether Kong runs as control plane", err)
+		}
+		if !controlPlane {
+			return nil, extension_kit.ToError(fmt.Sprintf("Kong instance '%s' isn't the control plane of a hybrid deployment, there are no data planes to wait for", instance.Name), nil)
+		}
+		state.WaitForDataPlanes = true
+		state.DataPlaneSyncTimeout = defaultDataPlaneSyncTimeout
+		if terminationConfig.DataPlaneSyncTimeout > 0 {
+			state.DataPlaneSyncTimeout = time.Duration(terminationConfig.DataPlaneSyncTimeout) * time.Millisecond
+		}
+	}
 
 	var consumer *kong.Consumer = nil
-	if config.Consumer != "" {
-		configuredConsumer := config.Consumer
+	if terminationConfig.Consumer != "" {
+		configuredConsumer := terminationConfig.Consumer
 		if len(configuredConsumer) > 0 {
-			consumer, err = instance.FindConsumer(&configuredConsumer)
+			consumer, err = instance.FindConsumer(ctx, &configuredConsumer)
 			if err != nil {
 				return nil, extension_kit.ToError(fmt.Sprintf("Failed to find consumer '%s' within Kong", configuredConsumer), err)
 			}
@@ -179,104 +257,300 @@ func (f RequestTerminationAction) Prepare(_ context.Context, state *RequestTermi
 	}
 
 	kongConfig := kong.Configuration{
-		"status_code": config.Status,
+		"status_code": terminationConfig.Status,
 	}
 
-	if isDefinedString(config.Body) {
-		kongConfig["body"] = config.Body
-	} else if isDefinedString(config.Message) {
-		kongConfig["message"] = config.Message
+	if isDefinedString(terminationConfig.Body) {
+		kongConfig["body"] = terminationConfig.Body
+	} else if isDefinedString(terminationConfig.Message) {
+		kongConfig["message"] = terminationConfig.Message
 	}
 
-	if isDefinedString(config.ContentType) {
-		kongConfig["content_type"] = config.ContentType
+	if isDefinedString(terminationConfig.ContentType) {
+		kongConfig["content_type"] = terminationConfig.ContentType
 	}
 
-	if isDefinedString(config.Trigger) {
-		kongConfig["trigger"] = config.Trigger
+	if isDefinedString(terminationConfig.Trigger) {
+		kongConfig["trigger"] = terminationConfig.Trigger
 	}
 
-	plugin, err := instance.CreatePluginAtAnyLevel(&kong.Plugin{
-		Name:    new("request-termination"),
-		Enabled: new(false),
-		Tags: utils.Strings([]string{
-			"created-by=steadybit",
-		}),
-		Service:  service,
-		Route:    route,
-		Consumer: consumer,
-		Config:   kongConfig,
-	})
+	routes := []*kong.Route{route}
+	if route == nil && isDefinedString(terminationConfig.RouteTag) {
+		routes, err = findRoutesByTag(ctx, instance, service, terminationConfig.RouteTag)
+		if err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to find routes of service '%s' tagged with '%s' within Kong", *requestedServiceId, terminationConfig.RouteTag), err)
+		}
+		if len(routes) == 0 {
+			return nil, extension_kit.ToError(fmt.Sprintf("No route of service '%s' is tagged with '%s'", *requestedServiceId, terminationConfig.RouteTag), nil)
+		}
+	}
+
+	if instance.IngressController {
+		// the controller reverts plugins created through the admin API, they have to be declared as Kubernetes resources
+		if consumer != nil {
+			return nil, extension_kit.ToError("Limiting requests to a consumer isn't supported for instances managed by the Kong Ingress Controller", nil)
+		}
+		objects, err := kubernetesObjectsOf(service, routes)
+		if err != nil {
+			return nil, extension_kit.ToError("Failed to find the Kubernetes objects to attach the plugin to", err)
+		}
+
+		state.ExecutionId = request.ExecutionId
+		state.InstanceName = instance.Name
+		state.Workspace = workspace
+		state.IngressController = true
+		state.PluginConfig = kongConfig
+		state.KongPluginName = kongPluginName(request.ExecutionId)
+		state.KubernetesObjects = objects
+		config.PinInstance(request.ExecutionId, pinnedInstance)
+		return nil, nil
+	}
+
+	dbLess, err := instance.IsDbLess(ctx)
 	if err != nil {
-		return nil, extension_kit.ToError("Failed to create plugin", err)
+		return nil, extension_kit.ToError("Failed to determine whether Kong runs without database", err)
 	}
+	if dbLess {
+		// the admin API of DB-less instances is read-only, the plugins get injected into the declarative configuration on start
+		plugins := make([]RequestTerminationPlugin, 0, len(routes))
+		for _, r := range routes {
+			plugin := RequestTerminationPlugin{PluginId: uuid.NewString(), ServiceId: *service.ID}
+			if r != nil {
+				plugin.RouteId = *r.ID
+			}
+			plugins = append(plugins, plugin)
+		}
 
-	var serviceId string
-	if service != nil {
-		serviceId = *service.ID
+		state.ExecutionId = request.ExecutionId
+		state.InstanceName = instance.Name
+		state.Workspace = workspace
+		state.Plugins = plugins
+		state.DbLess = true
+		state.PluginConfig = kongConfig
+		if consumer != nil {
+			state.ConsumerId = *consumer.ID
+		}
+		config.PinInstance(request.ExecutionId, pinnedInstance)
+		return nil, nil
 	}
-	var routeId string
-	if route != nil {
-		routeId = *route.ID
+
+	plugins := make([]RequestTerminationPlugin, 0, len(routes))
+	committed := false
+	defer func() {
+		// whatever fails after the first plugin got created must not leave plugins behind, even if the request got cancelled
+		if !committed {
+			if err := deletePlugins(context.WithoutCancel(ctx), instance, plugins); err != nil {
+				log.Error().Err(err).Msgf("Failed to roll back plugins created within Kong instance %s", instance.Name)
+			}
+		}
+	}()
+	for _, r := range routes {
+		plugin, err := instance.CreatePluginAtAnyLevel(ctx, &kong.Plugin{
+			// a preset ID makes the creation idempotent and therefore safe to retry
+			ID:      new(uuid.NewString()),
+			Name:    new(requestTerminationPlugin),
+			Enabled: new(false),
+			Tags: utils.Strings([]string{
+				createdBySteadybitTag,
+			}),
+			Service:  service,
+			Route:    r,
+			Consumer: consumer,
+			Config:   kongConfig,
+		})
+		if err != nil {
+			return nil, extension_kit.ToError("Failed to create plugin", err)
+		}
+
+		created := RequestTerminationPlugin{PluginId: *plugin.ID, ServiceId: *service.ID}
+		if r != nil {
+			created.RouteId = *r.ID
+		}
+		plugins = append(plugins, created)
 	}
 
+	state.ExecutionId = request.ExecutionId
 	state.InstanceName = instance.Name
-	state.ServiceId = serviceId
-	state.RouteId = routeId
-	state.PluginIds = []string{*plugin.ID}
+	state.Workspace = workspace
+	state.Plugins = plugins
+	committed = true
+	// keep using this instance until the execution stops, even if the instance configuration gets reloaded
+	config.PinInstance(request.ExecutionId, pinnedInstance)
 
 	return nil, nil
 }
 
-func (f RequestTerminationAction) Start(_ context.Context, state *RequestTerminationState) (*action_kit_api.StartResult, error) {
-	instance, err := config.FindInstanceByName(state.InstanceName)
+func (f RequestTerminationAction) Start(ctx context.Context, state *RequestTerminationState) (*action_kit_api.StartResult, error) {
+	instance, err := findStateInstance(state)
 	if err != nil {
-		return nil, extension_kit.ToError(fmt.Sprintf("Failed to find a configured instance named '%s'", state.InstanceName), err)
+		return nil, err
 	}
 
-	for _, pluginId := range state.PluginIds {
-		// try to update first at route level
-		if state.RouteId != "" {
-			_, err = instance.UpdatePluginForRoute(&state.RouteId, &kong.Plugin{
-				ID:      &pluginId,
-				Enabled: new(true),
-			})
-			if err != nil {
-				return nil, extension_kit.ToError(fmt.Sprintf("Failed to enable plugin within Kong for plugin ID '%s' at route level", pluginId), err)
+	var previousHashes map[string]bool
+	if state.WaitForDataPlanes {
+		previousHashes, err = getDataPlaneConfigHashes(ctx, instance)
+		if err != nil {
+			return nil, extension_kit.ToError("Failed to get the data planes of Kong", err)
+		}
+	}
+
+	if err := activatePlugins(ctx, instance, state); err != nil {
+		return nil, err
+	}
+
+	if state.WaitForDataPlanes {
+		if err := waitForDataPlaneSync(ctx, instance, previousHashes, time.Now(), state.DataPlaneSyncTimeout); err != nil {
+			// the attack isn't reported as active, so the fault must not stay in place
+			if rollbackErr := deactivatePlugins(context.WithoutCancel(ctx), instance, state); rollbackErr != nil {
+				log.Error().Err(rollbackErr).Msgf("Failed to roll back plugins within Kong instance %s", instance.Name)
 			}
-		} else if state.ServiceId != "" {
-			_, err = instance.UpdatePluginForService(&state.ServiceId, &kong.Plugin{
-				ID:      &pluginId,
-				Enabled: new(true),
-			})
-			if err != nil {
-				return nil, extension_kit.ToError(fmt.Sprintf("Failed to enable plugin within Kong for plugin ID '%s' at service level", pluginId), err)
+			return nil, extension_kit.ToError("Data planes didn't apply the plugins in time", err)
+		}
+	}
+	registerActiveFault(state, time.Now())
+	return nil, nil
+}
+
+// activatePlugins puts the fault in place, depending on how the configuration of the instance is managed.
+func activatePlugins(ctx context.Context, instance *config.Instance, state *RequestTerminationState) error {
+	if state.IngressController {
+		if err := attachKongPlugins(ctx, state); err != nil {
+			return extension_kit.ToError("Failed to attach the KongPlugin to Kubernetes objects", err)
+		}
+		return nil
+	}
+	if state.DbLess {
+		if err := injectDeclarativePlugins(ctx, instance, state); err != nil {
+			return extension_kit.ToError("Failed to inject plugins into the declarative configuration of Kong", err)
+		}
+		return nil
+	}
+
+	enabled := make([]RequestTerminationPlugin, 0, len(state.Plugins))
+	for _, plugin := range state.Plugins {
+		if err := setPluginEnabled(ctx, instance, plugin, true); err != nil {
+			// don't leave the fault partially active, even if the request got cancelled
+			if rollbackErr := disablePlugins(context.WithoutCancel(ctx), instance, enabled); rollbackErr != nil {
+				log.Error().Err(rollbackErr).Msgf("Failed to roll back plugins enabled within Kong instance %s", instance.Name)
 			}
+			return extension_kit.ToError(fmt.Sprintf("Failed to enable plugin within Kong for plugin ID '%s' at %s level", plugin.PluginId, plugin.level()), err)
 		}
+		enabled = append(enabled, plugin)
+	}
+	return nil
+}
+
+// deactivatePlugins reverts activatePlugins, leaving everything Stop cleans up in place.
+func deactivatePlugins(ctx context.Context, instance *config.Instance, state *RequestTerminationState) error {
+	if state.IngressController {
+		return detachKongPlugins(ctx, state)
+	}
+	if state.DbLess {
+		return removeDeclarativePlugins(ctx, instance, state)
 	}
+	return disablePlugins(ctx, instance, state.Plugins)
+}
+
+func (f RequestTerminationAction) Stop(ctx context.Context, state *RequestTerminationState) (*action_kit_api.StopResult, error) {
+	instance, err := findStateInstance(state)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.IngressController {
+		if err := detachKongPlugins(ctx, state); err != nil {
+			return nil, extension_kit.ToError("Failed to detach the KongPlugin from Kubernetes objects", err)
+		}
+	} else if state.DbLess {
+		if err := removeDeclarativePlugins(ctx, instance, state); err != nil {
+			return nil, extension_kit.ToError("Failed to remove plugins from the declarative configuration of Kong", err)
+		}
+	} else if err := deletePlugins(ctx, instance, state.Plugins); err != nil {
+		return nil, extension_kit.ToError("Failed to delete plugins within Kong", err)
+	}
+
+	unregisterActiveFault(state.ExecutionId)
+	config.UnpinInstance(state.ExecutionId)
 	return nil, nil
 }
 
-func (f RequestTerminationAction) Stop(_ context.Context, state *RequestTerminationState) (*action_kit_api.StopResult, error) {
-	instance, err := config.FindInstanceByName(state.InstanceName)
+// findStateInstance returns the instance the execution has been prepared with, scoped to the plugins' workspace.
+func findStateInstance(state *RequestTerminationState) (*config.Instance, error) {
+	instance, err := config.FindInstanceForExecution(state.ExecutionId, state.InstanceName)
 	if err != nil {
 		return nil, extension_kit.ToError(fmt.Sprintf("Failed to find a configured instance named '%s'", state.InstanceName), err)
 	}
+	return instance.InWorkspace(state.Workspace), nil
+}
+
+func (p RequestTerminationPlugin) level() string {
+	if p.RouteId != "" {
+		return "route"
+	}
+	return "service"
+}
+
+func setPluginEnabled(ctx context.Context, instance *config.Instance, plugin RequestTerminationPlugin, enabled bool) error {
+	update := &kong.Plugin{
+		ID:      &plugin.PluginId,
+		Enabled: &enabled,
+	}
+	var err error
+	if plugin.RouteId != "" {
+		_, err = instance.UpdatePluginForRoute(ctx, &plugin.RouteId, update)
+	} else {
+		_, err = instance.UpdatePluginForService(ctx, &plugin.ServiceId, update)
+	}
+	return err
+}
 
-	for _, pluginId := range state.PluginIds {
-		level := "service"
-		if state.RouteId != "" {
-			err = instance.DeletePluginForRoute(&state.RouteId, &pluginId)
-			level = "route"
-		} else if state.ServiceId != "" {
-			err = instance.DeletePluginForService(&state.ServiceId, &pluginId)
+// disablePlugins disables all given plugins, continuing past failures. All failures are returned joined together.
+func disablePlugins(ctx context.Context, instance *config.Instance, plugins []RequestTerminationPlugin) error {
+	var errs []error
+	for _, plugin := range plugins {
+		if err := setPluginEnabled(ctx, instance, plugin, false); err != nil {
+			errs = append(errs, fmt.Errorf("failed to disable plugin ID '%s' at %s level: %w", plugin.PluginId, plugin.level(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deletePlugins deletes all given plugins, continuing past failures so that a single unreachable plugin
+// doesn't keep the others active. All failures are returned joined together.
+func deletePlugins(ctx context.Context, instance *config.Instance, plugins []RequestTerminationPlugin) error {
+	var errs []error
+	for _, plugin := range plugins {
+		var err error
+		if plugin.RouteId != "" {
+			err = instance.DeletePluginForRoute(ctx, &plugin.RouteId, &plugin.PluginId)
+		} else {
+			err = instance.DeletePluginForService(ctx, &plugin.ServiceId, &plugin.PluginId)
 		}
 		if err != nil {
-			return nil, extension_kit.ToError(fmt.Sprintf("Failed to delete plugin within Kong for plugin ID '%s' at %s level", pluginId, level), err)
+			errs = append(errs, fmt.Errorf("failed to delete plugin ID '%s' at %s level: %w", plugin.PluginId, plugin.level(), err))
 		}
 	}
+	return errors.Join(errs...)
+}
 
-	return nil, nil
+func findRoutesByTag(ctx context.Context, instance *config.Instance, service *kong.Service, tag string) ([]*kong.Route, error) {
+	routes, err := instance.GetAllRoutesForService(ctx, service.ID)
+	if err != nil {
+		return nil, err
+	}
+	var matching []*kong.Route
+	for _, route := range routes {
+		if route.ID == nil {
+			continue
+		}
+		for _, t := range route.Tags {
+			if t != nil && *t == tag {
+				matching = append(matching, route)
+				break
+			}
+		}
+	}
+	return matching, nil
 }
 
 func isDefinedString(v any) bool {
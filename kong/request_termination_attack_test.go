@@ -5,13 +5,18 @@ package kong
 
 import (
 	"context"
+	"fmt"
+	"github.com/google/uuid"
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
 	"github.com/steadybit/action-kit/go/action_kit_sdk"
 	"github.com/steadybit/extension-kit/extutil"
 	"github.com/steadybit/extension-kong/v2/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func testPrepareFailsWhenServiceIsMissing(t *testing.T, instance *config.Instance) {
@@ -69,7 +74,7 @@ func testPrepareConfiguresDisabledPlugin(t *testing.T, instance *config.Instance
 		},
 	})
 
-	client, err := instance.GetClient()
+	client, err := instance.GetClient(context.Background())
 	require.NoError(t, err)
 
 	action := NewRequestTerminationAction()
@@ -82,7 +87,7 @@ func testPrepareConfiguresDisabledPlugin(t *testing.T, instance *config.Instance
 	assert.Nil(t, err)
 	assert.Nil(t, result)
 	assert.Equal(t, instance.Name, state.InstanceName)
-	plugin, err := client.Plugins.Get(context.Background(), &state.PluginIds[0])
+	plugin, err := client.Plugins.Get(context.Background(), &state.Plugins[0].PluginId)
 	require.NoError(t, err)
 	assert.Equal(t, "request-termination", *plugin.Name)
 	assert.Equal(t, false, *plugin.Enabled)
@@ -146,7 +151,7 @@ func testPrepareWithConsumer(t *testing.T, instance *config.Instance) {
 	action := NewRequestTerminationAction()
 	state := action.NewEmptyState()
 
-	client, err := instance.GetClient()
+	client, err := instance.GetClient(context.Background())
 	require.NoError(t, err)
 
 	// When
@@ -155,7 +160,7 @@ func testPrepareWithConsumer(t *testing.T, instance *config.Instance) {
 	// Then
 	assert.Nil(t, result)
 	assert.Nil(t, err)
-	plugin, err := client.Plugins.Get(context.Background(), &state.PluginIds[0])
+	plugin, err := client.Plugins.Get(context.Background(), &state.Plugins[0].PluginId)
 	require.NoError(t, err)
 	assert.Equal(t, *consumer.ID, *plugin.Consumer.ID)
 	assert.Equal(t, "banana", plugin.Config["trigger"])
@@ -188,7 +193,7 @@ func testPrepareWithRoute(t *testing.T, instance *config.Instance) {
 	action := NewRequestTerminationAction()
 	state := action.NewEmptyState()
 
-	client, err := instance.GetClient()
+	client, err := instance.GetClient(context.Background())
 	require.NoError(t, err)
 
 	// When
@@ -197,7 +202,7 @@ func testPrepareWithRoute(t *testing.T, instance *config.Instance) {
 	// Then
 	assert.Nil(t, err)
 	assert.Nil(t, result)
-	plugin, err := client.Plugins.Get(context.Background(), &state.PluginIds[0])
+	plugin, err := client.Plugins.Get(context.Background(), &state.Plugins[0].PluginId)
 	require.NoError(t, err)
 	assert.Equal(t, *route.ID, *plugin.Route.ID)
 	assert.Equal(t, "banana", plugin.Config["trigger"])
@@ -206,12 +211,93 @@ func testPrepareWithRoute(t *testing.T, instance *config.Instance) {
 	assert.Equal(t, "text/foobar", plugin.Config["content_type"])
 }
 
+func testPrepareWithRouteTag(t *testing.T, instance *config.Instance) {
+	// Given
+	service := configureService(t, instance, getTestService())
+	tagged := configureRoute(t, instance, getTestRoute(service))
+	otherTagged := getTestRoute(service)
+	otherTagged.Name = new("test-other")
+	otherTagged.Paths = []*string{new("/orders")}
+	otherTagged = configureRoute(t, instance, otherTagged)
+	untagged := getTestRoute(service)
+	untagged.Name = new("test-untagged")
+	untagged.Paths = []*string{new("/customers")}
+	untagged.Tags = nil
+	configureRoute(t, instance, untagged)
+	requestBody := extutil.JsonMangle(action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{
+			"status":   200,
+			"message":  "Hello from Kong extension",
+			"routeTag": "test",
+		},
+		Target: &action_kit_api.Target{
+			Attributes: map[string][]string{
+				"kong.instance.name": {instance.Name},
+				"kong.service.id":    {*service.ID},
+			},
+		},
+	})
+
+	action := NewServiceRequestTerminationAction()
+	state := action.NewEmptyState()
+
+	client, err := instance.GetClient(context.Background())
+	require.NoError(t, err)
+
+	// When
+	result, err := action.Prepare(context.TODO(), &state, requestBody)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Nil(t, result)
+	require.Len(t, state.Plugins, 2)
+	routeIds := make([]string, 0, len(state.Plugins))
+	for _, p := range state.Plugins {
+		assert.Equal(t, *service.ID, p.ServiceId)
+		plugin, err := client.Plugins.Get(context.Background(), &p.PluginId)
+		require.NoError(t, err)
+		assert.Equal(t, p.RouteId, *plugin.Route.ID)
+		assert.Equal(t, false, *plugin.Enabled)
+		routeIds = append(routeIds, p.RouteId)
+	}
+	assert.ElementsMatch(t, []string{*tagged.ID, *otherTagged.ID}, routeIds)
+}
+
+func testPrepareFailsWhenNoRouteIsTagged(t *testing.T, instance *config.Instance) {
+	// Given
+	service := configureService(t, instance, getTestService())
+	configureRoute(t, instance, getTestRoute(service))
+	requestBody := extutil.JsonMangle(action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{
+			"status":   200,
+			"routeTag": "unknown",
+		},
+		Target: &action_kit_api.Target{
+			Attributes: map[string][]string{
+				"kong.instance.name": {instance.Name},
+				"kong.service.id":    {*service.ID},
+			},
+		},
+	})
+
+	action := NewServiceRequestTerminationAction()
+	state := action.NewEmptyState()
+
+	// When
+	result, err := action.Prepare(context.TODO(), &state, requestBody)
+
+	// Then
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "No route of service")
+	assert.Empty(t, state.Plugins)
+}
+
 func testStartEnablesPlugin(t *testing.T, instance *config.Instance) {
 	// Given
 	action := NewRequestTerminationAction()
 	state := getSuccessfulPreparationState(t, instance)
 
-	client, err := instance.GetClient()
+	client, err := instance.GetClient(context.Background())
 	require.NoError(t, err)
 
 	// When
@@ -221,11 +307,15 @@ func testStartEnablesPlugin(t *testing.T, instance *config.Instance) {
 	assert.Nil(t, err)
 	assert.Nil(t, result)
 	assert.Equal(t, instance.Name, state.InstanceName)
-	assert.Equal(t, state.PluginIds[0], state.PluginIds[0])
-	plugin, err := client.Plugins.Get(context.Background(), &state.PluginIds[0])
+	require.Len(t, state.Plugins, 1)
+	servicePlugins, err := client.Plugins.ListAllForService(context.Background(), &state.Plugins[0].ServiceId)
+	require.NoError(t, err)
+	require.Len(t, servicePlugins, 1)
+	assert.Equal(t, *servicePlugins[0].ID, state.Plugins[0].PluginId)
+	plugin, err := client.Plugins.Get(context.Background(), &state.Plugins[0].PluginId)
 	require.NoError(t, err)
 	assert.Equal(t, true, *plugin.Enabled)
-	assert.NotNil(t, *plugin.Service.ID)
+	assert.Equal(t, state.Plugins[0].ServiceId, *plugin.Service.ID)
 	assert.Equal(t, 200.0, plugin.Config["status_code"])
 	assert.Equal(t, "Hello from Kong extension", plugin.Config["message"])
 }
@@ -268,7 +358,7 @@ func testStopDeletesPlugin(t *testing.T, instance *config.Instance) {
 
 	action := NewRequestTerminationAction().(action_kit_sdk.ActionWithStop[RequestTerminationState])
 
-	client, err := instance.GetClient()
+	client, err := instance.GetClient(context.Background())
 	require.NoError(t, err)
 
 	// When
@@ -277,6 +367,172 @@ func testStopDeletesPlugin(t *testing.T, instance *config.Instance) {
 	// Then
 	assert.Nil(t, result)
 	assert.Nil(t, err)
-	_, err = client.Plugins.Get(context.Background(), &state.PluginIds[0])
+	_, err = client.Plugins.Get(context.Background(), &state.Plugins[0].PluginId)
 	assert.Error(t, err)
 }
+
+func prepareFakeRouteTagState(t *testing.T) (*RequestTerminationState, error) {
+	requestBody := extutil.JsonMangle(action_kit_api.PrepareActionRequestBody{
+		ExecutionId: uuid.New(),
+		Config: map[string]any{
+			"status":   503,
+			"routeTag": "test",
+		},
+		Target: &action_kit_api.Target{
+			Attributes: map[string][]string{
+				"kong.instance.name": {"fake"},
+				"kong.service.id":    {"service"},
+			},
+		},
+	})
+	action := NewServiceRequestTerminationAction()
+	state := action.NewEmptyState()
+	_, err := action.Prepare(context.TODO(), &state, requestBody)
+	return &state, err
+}
+
+func TestPrepareRollsBackCreatedPluginsWhenCreationFails(t *testing.T) {
+	// Given
+	fake := newFakeKongAdmin(t, getFakeTaggedRoutes()...)
+	fake.failOn["create"] = 3
+
+	// When
+	state, err := prepareFakeRouteTagState(t)
+
+	// Then
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Failed to create plugin")
+	assert.Empty(t, state.Plugins)
+	assert.Equal(t, 0, fake.pluginCount())
+	assert.Equal(t, 2, fake.calls["delete"])
+}
+
+func TestStartRollsBackEnabledPluginsWhenEnablingFails(t *testing.T) {
+	// Given
+	fake := newFakeKongAdmin(t, getFakeTaggedRoutes()...)
+	state, err := prepareFakeRouteTagState(t)
+	require.NoError(t, err)
+	require.Len(t, state.Plugins, 3)
+	fake.failOn["update"] = 3
+
+	// When
+	result, err := NewServiceRequestTerminationAction().Start(context.TODO(), state)
+
+	// Then
+	assert.Nil(t, result)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("Failed to enable plugin within Kong for plugin ID '%s' at route level", state.Plugins[2].PluginId))
+	assert.Equal(t, 0, fake.enabledPlugins())
+	assert.Equal(t, 3, fake.pluginCount())
+}
+
+func TestStopDeletesRemainingPluginsWhenDeletionFails(t *testing.T) {
+	// Given
+	fake := newFakeKongAdmin(t, getFakeTaggedRoutes()...)
+	state, err := prepareFakeRouteTagState(t)
+	require.NoError(t, err)
+	action := NewServiceRequestTerminationAction().(action_kit_sdk.ActionWithStop[RequestTerminationState])
+	_, err = action.Start(context.TODO(), state)
+	require.NoError(t, err)
+	fake.failOn["delete"] = 1
+
+	// When
+	result, err := action.Stop(context.TODO(), state)
+
+	// Then
+	assert.Nil(t, result)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Failed to delete plugins within Kong")
+	assert.Equal(t, 1, fake.pluginCount())
+	assert.Equal(t, 3, fake.calls["delete"])
+}
+
+func TestPrepareFailsWhenPluginIsNotAvailable(t *testing.T) {
+	// Given
+	fake := newFakeKongAdmin(t, getFakeTaggedRoutes()...)
+	fake.availablePlugins = []string{"cors", "rate-limiting"}
+
+	// When
+	_, err := prepareFakeRouteTagState(t)
+
+	// Then
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "The request-termination plugin isn't available on Kong instance 'fake'")
+	assert.Equal(t, 0, fake.calls["create"])
+}
+
+func TestStopUsesPinnedInstanceAfterReload(t *testing.T) {
+	// Given
+	fake := newFakeKongAdmin(t, getFakeTaggedRoutes()...)
+	state, err := prepareFakeRouteTagState(t)
+	require.NoError(t, err)
+	action := NewServiceRequestTerminationAction().(action_kit_sdk.ActionWithStop[RequestTerminationState])
+	_, err = action.Start(context.TODO(), state)
+	require.NoError(t, err)
+	config.SetInstances([]config.Instance{{Name: "fake", BaseUrl: "http://127.0.0.1:1"}})
+
+	// When
+	result, err := action.Stop(context.TODO(), state)
+
+	// Then
+	assert.Nil(t, result)
+	require.NoError(t, err)
+	assert.Equal(t, 0, fake.pluginCount())
+}
+
+func TestRequestTerminationAgainstKonnect(t *testing.T) {
+	// Given
+	fake := newFakeKonnect(t, getFakeTaggedRoutes()...)
+	action := NewServiceRequestTerminationAction().(action_kit_sdk.ActionWithStop[RequestTerminationState])
+
+	// When
+	state, err := prepareFakeRouteTagState(t)
+	require.NoError(t, err)
+	_, err = action.Start(context.TODO(), state)
+	require.NoError(t, err)
+
+	// Then
+	assert.Equal(t, 3, fake.enabledPlugins())
+	_, err = action.Stop(context.TODO(), state)
+	require.NoError(t, err)
+	assert.Equal(t, 0, fake.pluginCount())
+}
+
+func TestPrepareAbortsWhenAdminApiHangs(t *testing.T) {
+	// Given
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+	config.SetInstances([]config.Instance{{Name: "hanging", BaseUrl: server.URL}})
+	t.Cleanup(resetGlobalInstanceConfiguration)
+	previousTimeout := config.Config.AdminApiTimeout
+	config.Config.AdminApiTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { config.Config.AdminApiTimeout = previousTimeout })
+
+	requestBody := extutil.JsonMangle(action_kit_api.PrepareActionRequestBody{
+		Target: &action_kit_api.Target{
+			Attributes: map[string][]string{
+				"kong.instance.name": {"hanging"},
+				"kong.service.id":    {"service"},
+			},
+		},
+	})
+	action := NewRequestTerminationAction()
+	state := action.NewEmptyState()
+
+	// When
+	started := time.Now()
+	result, err := action.Prepare(context.TODO(), &state, requestBody)
+
+	// Then
+	assert.Nil(t, result)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Failed to find service")
+	assert.Less(t, time.Since(started), 5*time.Second)
+}
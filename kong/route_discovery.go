@@ -12,6 +12,7 @@ import (
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-kit/extbuild"
 	"github.com/steadybit/extension-kong/v2/config"
+	"github.com/steadybit/extension-kong/v2/extmetrics"
 	"time"
 )
 
@@ -27,6 +28,7 @@ func NewRouteDiscovery() discovery_kit_sdk.TargetDiscovery {
 	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
 		discovery_kit_sdk.WithRefreshTargetsNow(),
 		discovery_kit_sdk.WithRefreshTargetsInterval(context.Background(), 5*time.Minute),
+		discovery_kit_sdk.WithRefreshTargetsTrigger(context.Background(), config.SubscribeInstanceChanges(), 5*time.Second),
 	)
 }
 
@@ -49,6 +51,7 @@ func (*routeDiscovery) DescribeTarget() discovery_kit_api.TargetDescription {
 		Table: discovery_kit_api.Table{
 			Columns: []discovery_kit_api.Column{
 				{Attribute: "kong.instance.name"},
+				{Attribute: "kong.workspace.name"},
 				{Attribute: "kong.route.name"},
 				{Attribute: "kong.route.id"},
 				{Attribute: "kong.service.name"},
@@ -67,32 +70,52 @@ func (*routeDiscovery) DescribeTarget() discovery_kit_api.TargetDescription {
 	}
 }
 
-func (*routeDiscovery) DiscoverTargets(_ context.Context) ([]discovery_kit_api.Target, error) {
+func (*routeDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	ctx, end := extmetrics.BeginDiscovery(ctx, RouteTargetID)
 	var targets = make([]discovery_kit_api.Target, 0, 1000)
-	for _, instance := range config.Instances {
-		targets = append(targets, getRouteTargets(&instance)...)
+	for _, instance := range config.GetInstances() {
+		targets = append(targets, getRouteTargets(ctx, &instance)...)
 	}
+	end(len(targets))
 	return targets, nil
 }
 
-func getRouteTargets(instance *config.Instance) []discovery_kit_api.Target {
-	services, err := instance.GetServices()
+func getRouteTargets(ctx context.Context, instance *config.Instance) []discovery_kit_api.Target {
+	workspaces, err := instance.GetWorkspaces(ctx)
 	if err != nil {
-		log.Err(err).Msgf("Failed to get services from Kong instance %s (%s)", instance.Name, instance.BaseUrl)
+		log.Err(err).Msgf("Failed to get workspaces from Kong instance %s (%s)", instance.Name, instance.Origin())
+		extmetrics.ObserveDiscoveryFailure(ctx, RouteTargetID, instance.Name, err)
+		return []discovery_kit_api.Target{}
+	}
+
+	targets := make([]discovery_kit_api.Target, 0, 1000)
+	for _, workspace := range workspaces {
+		targets = append(targets, getRouteTargetsOfWorkspace(ctx, instance.InWorkspace(workspace))...)
+	}
+	return discovery_kit_commons.ApplyAttributeExcludes(targets, config.Config.DiscoveryAttributesExcludesRoute)
+}
+
+func getRouteTargetsOfWorkspace(ctx context.Context, instance *config.Instance) []discovery_kit_api.Target {
+	services, err := instance.GetServices(ctx)
+	if err != nil {
+		log.Err(err).Msgf("Failed to get services from Kong instance %s (%s) in workspace %s", instance.Name, instance.Origin(), instance.WorkspaceName())
+		extmetrics.ObserveDiscoveryFailure(ctx, RouteTargetID, instance.Name, err)
 		return []discovery_kit_api.Target{}
 	}
 
 	targets := make([]discovery_kit_api.Target, 0, len(services)*10)
 	for _, service := range services {
-		routes, _, err := instance.GetRoutesForService(service.ID)
+		routes, _, err := instance.GetRoutesForService(ctx, service.ID)
 		if err != nil {
-			log.Err(err).Msgf("Failed to get routes from Kong instance %s (%s) for service %s (%s)", instance.Name, instance.BaseUrl, *service.Name, *service.ID)
+			log.Err(err).Msgf("Failed to get routes from Kong instance %s (%s) for service %s (%s)", instance.Name, instance.Origin(), *service.Name, *service.ID)
+			extmetrics.ObserveDiscoveryFailure(ctx, RouteTargetID, instance.Name, err)
 			continue
 		}
 
 		for _, route := range routes {
 			attributes := make(map[string][]string)
 			attributes["kong.instance.name"] = []string{instance.Name}
+			attributes["kong.workspace.name"] = []string{instance.WorkspaceName()}
 			if route.ID != nil {
 				attributes["kong.route.id"] = []string{*route.ID}
 			}
@@ -115,6 +138,7 @@ func getRouteTargets(instance *config.Instance) []discovery_kit_api.Target {
 			for _, method := range route.Methods {
 				attributes["kong.route.method"] = append(attributes["kong.route.method"], *method)
 			}
+			addKubernetesObjectAttributes(attributes, "kong.route", route.Tags)
 
 			if route.ID != nil && route.Name != nil {
 				targets = append(targets, discovery_kit_api.Target{
@@ -127,5 +151,5 @@ func getRouteTargets(instance *config.Instance) []discovery_kit_api.Target {
 
 		}
 	}
-	return discovery_kit_commons.ApplyAttributeExcludes(targets, config.Config.DiscoveryAttributesExcludesRoute)
+	return targets
 }
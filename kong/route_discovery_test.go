@@ -4,6 +4,7 @@
 package kong
 
 import (
+	"context"
 	"github.com/steadybit/extension-kong/v2/config"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -16,7 +17,7 @@ func testDiscoverRoutes(t *testing.T, instance *config.Instance) {
 	config.Config.DiscoveryAttributesExcludesRoute = []string{"kong.service.id"}
 
 	// When
-	targets := getRouteTargets(instance)
+	targets := getRouteTargets(context.Background(), instance)
 
 	// Then
 	assert.NotEmpty(t, targets)
@@ -28,6 +29,6 @@ func testDiscoverRoutes(t *testing.T, instance *config.Instance) {
 }
 
 func testDiscoverNoRoutesWhenNoneAreConfigured(t *testing.T, instance *config.Instance) {
-	targets := getRouteTargets(instance)
+	targets := getRouteTargets(context.Background(), instance)
 	assert.Empty(t, targets)
 }
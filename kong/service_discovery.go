@@ -12,6 +12,7 @@ import (
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-kit/extbuild"
 	"github.com/steadybit/extension-kong/v2/config"
+	"github.com/steadybit/extension-kong/v2/extmetrics"
 	"strconv"
 	"strings"
 	"time"
@@ -29,6 +30,7 @@ func NewServiceDiscovery() discovery_kit_sdk.TargetDiscovery {
 	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
 		discovery_kit_sdk.WithRefreshTargetsNow(),
 		discovery_kit_sdk.WithRefreshTargetsInterval(context.Background(), 150*time.Second),
+		discovery_kit_sdk.WithRefreshTargetsTrigger(context.Background(), config.SubscribeInstanceChanges(), 5*time.Second),
 	)
 }
 
@@ -53,6 +55,7 @@ func (*serviceDiscovery) DescribeTarget() discovery_kit_api.TargetDescription {
 				{Attribute: "kong.service.name"},
 				{Attribute: "kong.service.url"},
 				{Attribute: "kong.instance.name"},
+				{Attribute: "kong.workspace.name"},
 				{Attribute: "kong.service.tag"},
 				{Attribute: "kong.service.enabled"},
 			},
@@ -66,18 +69,36 @@ func (*serviceDiscovery) DescribeTarget() discovery_kit_api.TargetDescription {
 	}
 }
 
-func (*serviceDiscovery) DiscoverTargets(_ context.Context) ([]discovery_kit_api.Target, error) {
+func (*serviceDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	ctx, end := extmetrics.BeginDiscovery(ctx, ServiceTargetId)
 	var targets = make([]discovery_kit_api.Target, 0, 100)
-	for _, instance := range config.Instances {
-		targets = append(targets, getServiceTargets(&instance)...)
+	for _, instance := range config.GetInstances() {
+		targets = append(targets, getServiceTargets(ctx, &instance)...)
 	}
+	end(len(targets))
 	return targets, nil
 }
 
-func getServiceTargets(instance *config.Instance) []discovery_kit_api.Target {
-	services, err := instance.GetServices()
+func getServiceTargets(ctx context.Context, instance *config.Instance) []discovery_kit_api.Target {
+	workspaces, err := instance.GetWorkspaces(ctx)
 	if err != nil {
-		log.Err(err).Msgf("Failed to get services from Kong instance %s (%s)", instance.Name, instance.BaseUrl)
+		log.Err(err).Msgf("Failed to get workspaces from Kong instance %s (%s)", instance.Name, instance.Origin())
+		extmetrics.ObserveDiscoveryFailure(ctx, ServiceTargetId, instance.Name, err)
+		return []discovery_kit_api.Target{}
+	}
+
+	targets := make([]discovery_kit_api.Target, 0, 100)
+	for _, workspace := range workspaces {
+		targets = append(targets, getServiceTargetsOfWorkspace(ctx, instance.InWorkspace(workspace))...)
+	}
+	return discovery_kit_commons.ApplyAttributeExcludes(targets, config.Config.DiscoveryAttributesExcludesService)
+}
+
+func getServiceTargetsOfWorkspace(ctx context.Context, instance *config.Instance) []discovery_kit_api.Target {
+	services, err := instance.GetServices(ctx)
+	if err != nil {
+		log.Err(err).Msgf("Failed to get services from Kong instance %s (%s) in workspace %s", instance.Name, instance.Origin(), instance.WorkspaceName())
+		extmetrics.ObserveDiscoveryFailure(ctx, ServiceTargetId, instance.Name, err)
 		return []discovery_kit_api.Target{}
 	}
 
@@ -86,6 +107,7 @@ func getServiceTargets(instance *config.Instance) []discovery_kit_api.Target {
 
 		attributes := make(map[string][]string)
 		attributes["kong.instance.name"] = []string{instance.Name}
+		attributes["kong.workspace.name"] = []string{instance.WorkspaceName()}
 		if service.ID != nil {
 			attributes["kong.service.id"] = []string{*service.ID}
 		}
@@ -121,6 +143,7 @@ func getServiceTargets(instance *config.Instance) []discovery_kit_api.Target {
 		for _, tag := range service.Tags {
 			attributes["kong.service.tag"] = append(attributes["kong.service.tag"], *tag)
 		}
+		addKubernetesObjectAttributes(attributes, "kong.service", service.Tags)
 
 		targets[i] = discovery_kit_api.Target{
 			Id:         fmt.Sprintf("%s-%s", instance.Name, *service.ID),
@@ -129,5 +152,5 @@ func getServiceTargets(instance *config.Instance) []discovery_kit_api.Target {
 			Attributes: attributes,
 		}
 	}
-	return discovery_kit_commons.ApplyAttributeExcludes(targets, config.Config.DiscoveryAttributesExcludesService)
+	return targets
 }
@@ -4,8 +4,13 @@
 package kong
 
 import (
+	"context"
+	"github.com/kong/go-kong/kong"
 	"github.com/steadybit/extension-kong/v2/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -14,7 +19,7 @@ func testDiscoverServices(t *testing.T, instance *config.Instance) {
 	configureService(t, instance, getTestService())
 	config.Config.DiscoveryAttributesExcludesService = []string{"kong.service.id"}
 	// When
-	targets := getServiceTargets(instance)
+	targets := getServiceTargets(context.Background(), instance)
 
 	// Then
 	assert.NotEmpty(t, targets)
@@ -26,6 +31,30 @@ func testDiscoverServices(t *testing.T, instance *config.Instance) {
 }
 
 func testDiscoverNoServicesWhenNoneAreConfigured(t *testing.T, instance *config.Instance) {
-	targets := getServiceTargets(instance)
+	targets := getServiceTargets(context.Background(), instance)
 	assert.Empty(t, targets)
 }
+
+func TestDiscoverServicesOfAllWorkspaces(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/workspaces/":
+			writeJSON(w, http.StatusOK, map[string]any{"data": []*kong.Workspace{{Name: new("default")}, {Name: new("team-a")}}})
+		case "/default/services":
+			writeJSON(w, http.StatusOK, map[string]any{"data": []*kong.Service{{ID: new("service-1"), Name: new("billing")}}})
+		case "/team-a/services":
+			writeJSON(w, http.StatusOK, map[string]any{"data": []*kong.Service{{ID: new("service-2"), Name: new("checkout")}}})
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not found"})
+		}
+	}))
+	t.Cleanup(server.Close)
+	instance := &config.Instance{Name: "enterprise", BaseUrl: server.URL, Workspaces: []string{config.AllWorkspaces}}
+
+	targets := getServiceTargets(context.Background(), instance)
+
+	require.Len(t, targets, 2)
+	assert.Equal(t, []string{"default"}, targets[0].Attributes["kong.workspace.name"])
+	assert.Equal(t, []string{"team-a"}, targets[1].Attributes["kong.workspace.name"])
+	assert.Equal(t, "checkout", targets[1].Label)
+}
@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-kit/extbuild"
+)
+
+type ServiceDriftCheckAction struct {
+}
+
+func NewServiceDriftCheckAction() action_kit_sdk.Action[DriftCheckState] {
+	return ServiceDriftCheckAction{}
+}
+
+var _ action_kit_sdk.Action[DriftCheckState] = (*ServiceDriftCheckAction)(nil)
+var _ action_kit_sdk.ActionWithStop[DriftCheckState] = (*ServiceDriftCheckAction)(nil)
+
+func (f ServiceDriftCheckAction) NewEmptyState() DriftCheckState {
+	return DriftCheckState{}
+}
+
+func (f ServiceDriftCheckAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          "com.steadybit.extension_kong.services.drift_check",
+		Label:       "Configuration Drift",
+		Description: "Verify that the configuration of a Kong service, including its routes and plugins, is the same at the end as at the start of the check, apart from the plugins of the extension.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        new(ServiceIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: ServiceTargetId,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "service-id",
+					Description: new("Find service by id"),
+					Query:       "kong.service.id=\"\"",
+				},
+				{
+					Label:       "service-name",
+					Description: new("Find service by name"),
+					Query:       "kong.service.name=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Kong"),
+		TimeControl: action_kit_api.TimeControlExternal,
+		Kind:        action_kit_api.Check,
+		Parameters:  driftCheckParameters(),
+		Prepare:     action_kit_api.MutatingEndpointReference{},
+		Start:       action_kit_api.MutatingEndpointReference{},
+		Stop:        new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (f ServiceDriftCheckAction) Prepare(ctx context.Context, state *DriftCheckState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	return NewDriftCheckAction().Prepare(ctx, state, request)
+}
+
+func (f ServiceDriftCheckAction) Start(ctx context.Context, state *DriftCheckState) (*action_kit_api.StartResult, error) {
+	return NewDriftCheckAction().Start(ctx, state)
+}
+
+func (f ServiceDriftCheckAction) Stop(ctx context.Context, state *DriftCheckState) (*action_kit_api.StopResult, error) {
+	return NewDriftCheckAction().(action_kit_sdk.ActionWithStop[DriftCheckState]).Stop(ctx, state)
+}
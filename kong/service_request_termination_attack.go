@@ -103,6 +103,29 @@ func (f ServiceTerminationAction) Describe() action_kit_api.ActionDescription {
 				Description: new("When not set, the plugin always activates. When set to a string, the plugin will activate exclusively on requests containing either a header or a query parameter that is named the string."),
 				Advanced:    new(true),
 			},
+			{
+				Label:       "Route Tag",
+				Name:        "routeTag",
+				Type:        action_kit_api.ActionParameterTypeString,
+				Description: new("When not set, requests are terminated for the whole service. When set, requests are terminated only for the routes of the service carrying this tag, with one plugin per route."),
+				Advanced:    new(true),
+			},
+			{
+				Label:        "Wait for data planes",
+				Name:         "waitForDataPlanes",
+				Type:         action_kit_api.ActionParameterTypeBoolean,
+				Description:  new("In hybrid deployments, wait until all connected data planes applied the plugin before reporting the attack as active."),
+				Advanced:     new(true),
+				DefaultValue: new("false"),
+			},
+			{
+				Label:        "Data plane sync timeout",
+				Name:         "dataPlaneSyncTimeout",
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				Description:  new("How long to wait for the data planes to apply the plugin."),
+				Advanced:     new(true),
+				DefaultValue: new("60s"),
+			},
 		},
 		Prepare: action_kit_api.MutatingEndpointReference{},
 		Start:   action_kit_api.MutatingEndpointReference{},
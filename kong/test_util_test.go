@@ -34,7 +34,7 @@ func getTestRoute(service *kong.Service) *kong.Route {
 }
 
 func configureService(t *testing.T, instance *config.Instance, service *kong.Service) *kong.Service {
-	client, err := instance.GetClient()
+	client, err := instance.GetClient(context.Background())
 	require.NoError(t, err)
 
 	createdService, err := client.Services.Create(context.Background(), service)
@@ -43,7 +43,7 @@ func configureService(t *testing.T, instance *config.Instance, service *kong.Ser
 }
 
 func configureRoute(t *testing.T, instance *config.Instance, route *kong.Route) *kong.Route {
-	client, err := instance.GetClient()
+	client, err := instance.GetClient(context.Background())
 	require.NoError(t, err)
 
 	createdRoute, err := client.Routes.Create(context.Background(), route)
@@ -58,7 +58,7 @@ func getTestConsumer() *kong.Consumer {
 }
 
 func configureConsumer(t *testing.T, instance *config.Instance, consumer *kong.Consumer) *kong.Consumer {
-	client, err := instance.GetClient()
+	client, err := instance.GetClient(context.Background())
 	require.NoError(t, err)
 
 	createdConsumer, err := client.Consumers.Create(context.Background(), consumer)
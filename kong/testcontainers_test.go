@@ -12,6 +12,7 @@ import (
 	"github.com/testcontainers/testcontainers-go"
 	tcnetwork "github.com/testcontainers/testcontainers-go/network"
 	"github.com/testcontainers/testcontainers-go/wait"
+	"slices"
 	"testing"
 	"time"
 )
@@ -45,7 +46,7 @@ func WithTestContainers(t *testing.T, testCases []WithTestContainersCase) {
 	defer tcs.Terminate(t, context.Background())
 
 	instance := tcs.Instance
-	config.Instances = append(config.Instances, *instance)
+	config.SetInstances(append(slices.Clone(config.GetInstances()), *instance))
 	defer resetGlobalInstanceConfiguration()
 
 	for _, tc := range testCases {
@@ -57,11 +58,11 @@ func WithTestContainers(t *testing.T, testCases []WithTestContainersCase) {
 }
 
 func resetGlobalInstanceConfiguration() {
-	config.Instances = []config.Instance{}
+	config.SetInstances([]config.Instance{})
 }
 
 func cleanupKong(t *testing.T, instance *config.Instance) {
-	client, err := instance.GetClient()
+	client, err := instance.GetClient(context.Background())
 	require.NoError(t, err)
 
 	// delete all routes
@@ -0,0 +1,339 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/kong/go-kong/kong"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	extension_kit "github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extconversion"
+	"github.com/steadybit/extension-kong/v2/config"
+	"slices"
+	"time"
+)
+
+const defaultRecoveryTimeout = 30 * time.Second
+
+type UpstreamHealthCheckAction struct {
+}
+
+type UpstreamHealthCheckState struct {
+	ExecutionId  uuid.UUID
+	InstanceName string
+	Workspace    string
+	UpstreamId   string
+	UpstreamName string
+	// MinHealthyTargets fails the check as soon as fewer targets are available.
+	MinHealthyTargets int
+	// RecoveryTarget, if set, fails the check if it stays unhealthy for longer than RecoveryTimeout.
+	RecoveryTarget  string
+	RecoveryTimeout time.Duration
+	UnhealthySince  time.Time
+}
+
+type UpstreamHealthCheckConfig struct {
+	MinHealthyTargets int
+	Target            string
+	// RecoveryTimeout is in milliseconds.
+	RecoveryTimeout int
+}
+
+func NewUpstreamHealthCheckAction() action_kit_sdk.Action[UpstreamHealthCheckState] {
+	return UpstreamHealthCheckAction{}
+}
+
+var _ action_kit_sdk.Action[UpstreamHealthCheckState] = (*UpstreamHealthCheckAction)(nil)
+var _ action_kit_sdk.ActionWithStatus[UpstreamHealthCheckState] = (*UpstreamHealthCheckAction)(nil)
+var _ action_kit_sdk.ActionWithStop[UpstreamHealthCheckState] = (*UpstreamHealthCheckAction)(nil)
+
+func (f UpstreamHealthCheckAction) NewEmptyState() UpstreamHealthCheckState {
+	return UpstreamHealthCheckState{}
+}
+
+func (f UpstreamHealthCheckAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          "com.steadybit.extension_kong.services.upstream_health",
+		Label:       "Upstream Health",
+		Description: "Check the health Kong's active and passive health checks report for the targets of the upstream a service balances its requests through.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        new(ServiceIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: ServiceTargetId,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "service-id",
+					Description: new("Find service by id"),
+					Query:       "kong.service.id=\"\"",
+				},
+				{
+					Label:       "service-name",
+					Description: new("Find service by name"),
+					Query:       "kong.service.name=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Kong"),
+		TimeControl: action_kit_api.TimeControlExternal,
+		Kind:        action_kit_api.Check,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Label:        "Duration",
+				Name:         "duration",
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				Advanced:     new(false),
+				Required:     new(true),
+				DefaultValue: new("30s"),
+			},
+			{
+				Label:        "Min healthy targets",
+				Name:         "minHealthyTargets",
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				Description:  new("The check fails as soon as fewer targets of the upstream are healthy."),
+				Advanced:     new(false),
+				Required:     new(true),
+				DefaultValue: new("1"),
+				MinValue:     new(0),
+			},
+			{
+				Label:       "Target",
+				Name:        "target",
+				Type:        action_kit_api.ActionParameterTypeString,
+				Description: new("Optional target of the upstream, e.g., 10.0.0.1:8080, which has to recover within the recovery timeout whenever it turns unhealthy."),
+				Advanced:    new(false),
+				Required:    new(false),
+			},
+			{
+				Label:        "Recovery timeout",
+				Name:         "recoveryTimeout",
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				Description:  new("How long the target may stay unhealthy."),
+				Advanced:     new(false),
+				DefaultValue: new("30s"),
+			},
+		},
+		Widgets: new(action_kit_api.Widgets{
+			action_kit_api.StateOverTimeWidget{
+				Type:  action_kit_api.ComSteadybitWidgetStateOverTime,
+				Title: "Upstream Target Health",
+				Identity: action_kit_api.StateOverTimeWidgetIdentityConfig{
+					From: "target",
+				},
+				Label: action_kit_api.StateOverTimeWidgetLabelConfig{
+					From: "target",
+				},
+				State: action_kit_api.StateOverTimeWidgetStateConfig{
+					From: "state",
+				},
+				Tooltip: action_kit_api.StateOverTimeWidgetTooltipConfig{
+					From: "tooltip",
+				},
+				Value: new(action_kit_api.StateOverTimeWidgetValueConfig{
+					Hide: new(true),
+				}),
+			},
+		}),
+		Prepare: action_kit_api.MutatingEndpointReference{},
+		Start:   action_kit_api.MutatingEndpointReference{},
+		Status: new(action_kit_api.MutatingEndpointReferenceWithCallInterval{
+			CallInterval: new("2s"),
+		}),
+		Stop: new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (f UpstreamHealthCheckAction) Prepare(ctx context.Context, state *UpstreamHealthCheckState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	instanceName := findFirstValue(request.Target.Attributes, "kong.instance.name")
+	if instanceName == nil {
+		return nil, extension_kit.ToError("Missing target attribute 'kong.instance.name'", nil)
+	}
+	requestedServiceId := findFirstValue(request.Target.Attributes, "kong.service.id")
+	if requestedServiceId == nil {
+		return nil, extension_kit.ToError("Missing target attribute 'kong.service.id' required.", nil)
+	}
+
+	instance, err := config.FindInstanceByName(*instanceName)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to find a configured instance named '%s'", *instanceName), err)
+	}
+	if instance.IsKonnect() {
+		return nil, extension_kit.ToError(fmt.Sprintf("Kong instance '%s' is a Konnect control plane, which doesn't report the health of upstream targets", instance.Name), nil)
+	}
+	pinnedInstance := *instance
+	workspace := ""
+	if requestedWorkspace := findFirstValue(request.Target.Attributes, "kong.workspace.name"); requestedWorkspace != nil && instance.UsesWorkspaces() {
+		workspace = *requestedWorkspace
+	}
+	instance = instance.InWorkspace(workspace)
+
+	var checkConfig UpstreamHealthCheckConfig
+	if err := extconversion.Convert(request.Config, &checkConfig); err != nil {
+		return nil, extension_kit.ToError("Failed to unmarshal the config.", err)
+	}
+
+	service, err := instance.FindService(ctx, requestedServiceId)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to find service '%s' within Kong", *requestedServiceId), err)
+	}
+	if service.Host == nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Service '%s' has no host", *requestedServiceId), nil)
+	}
+	// services balance their requests through the upstream named like their host
+	upstream, err := instance.FindUpstream(ctx, service.Host)
+	if err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Failed to find the upstream '%s' of service '%s', the host of the service has to name an upstream", *service.Host, *requestedServiceId), err)
+	}
+
+	if len(checkConfig.Target) > 0 {
+		targets, err := instance.GetUpstreamHealth(ctx, upstream.ID)
+		if err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to get the health of upstream '%s'", *service.Host), err)
+		}
+		if !slices.ContainsFunc(targets, func(target *kong.UpstreamNodeHealth) bool {
+			return target.Target != nil && *target.Target == checkConfig.Target
+		}) {
+			return nil, extension_kit.ToError(fmt.Sprintf("Upstream '%s' has no target '%s'", *service.Host, checkConfig.Target), nil)
+		}
+	}
+
+	state.ExecutionId = request.ExecutionId
+	state.InstanceName = instance.Name
+	state.Workspace = workspace
+	state.UpstreamId = *upstream.ID
+	state.UpstreamName = *service.Host
+	state.MinHealthyTargets = checkConfig.MinHealthyTargets
+	state.RecoveryTarget = checkConfig.Target
+	state.RecoveryTimeout = defaultRecoveryTimeout
+	if checkConfig.RecoveryTimeout > 0 {
+		state.RecoveryTimeout = time.Duration(checkConfig.RecoveryTimeout) * time.Millisecond
+	}
+	// keep checking this instance until the check stops, even if the instance configuration gets reloaded
+	config.PinInstance(request.ExecutionId, pinnedInstance)
+	return nil, nil
+}
+
+func (f UpstreamHealthCheckAction) Start(ctx context.Context, state *UpstreamHealthCheckState) (*action_kit_api.StartResult, error) {
+	metrics, checkErr, err := checkUpstreamHealth(ctx, state, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &action_kit_api.StartResult{Metrics: &metrics, Error: checkErr}, nil
+}
+
+func (f UpstreamHealthCheckAction) Status(ctx context.Context, state *UpstreamHealthCheckState) (*action_kit_api.StatusResult, error) {
+	metrics, checkErr, err := checkUpstreamHealth(ctx, state, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &action_kit_api.StatusResult{Metrics: &metrics, Error: checkErr}, nil
+}
+
+func (f UpstreamHealthCheckAction) Stop(_ context.Context, state *UpstreamHealthCheckState) (*action_kit_api.StopResult, error) {
+	config.UnpinInstance(state.ExecutionId)
+	return stopUpstreamHealthCheck(state, time.Now()), nil
+}
+
+// stopUpstreamHealthCheck fails the check if the recovery target is still unhealthy for longer than the recovery
+// timeout. A target which tripped more recently could still recover, which is reported as a warning.
+func stopUpstreamHealthCheck(state *UpstreamHealthCheckState, now time.Time) *action_kit_api.StopResult {
+	if state.UnhealthySince.IsZero() {
+		return nil
+	}
+	if now.Sub(state.UnhealthySince) > state.RecoveryTimeout {
+		return &action_kit_api.StopResult{Error: &action_kit_api.ActionKitError{
+			Title:  fmt.Sprintf("Target '%s' of upstream '%s' didn't recover within %s.", state.RecoveryTarget, state.UpstreamName, state.RecoveryTimeout),
+			Status: new(action_kit_api.Failed),
+		}}
+	}
+	return &action_kit_api.StopResult{Summary: &action_kit_api.Summary{
+		Level: action_kit_api.SummaryLevelWarning,
+		Text:  fmt.Sprintf("Target '%s' of upstream '%s' was still recovering when the check ended, unhealthy for %s.", state.RecoveryTarget, state.UpstreamName, now.Sub(state.UnhealthySince).Round(time.Second)),
+	}}
+}
+
+// checkUpstreamHealth reports the health of all targets and returns a failure once the thresholds are violated.
+func checkUpstreamHealth(ctx context.Context, state *UpstreamHealthCheckState, now time.Time) (action_kit_api.Metrics, *action_kit_api.ActionKitError, error) {
+	instance, err := config.FindInstanceForExecution(state.ExecutionId, state.InstanceName)
+	if err != nil {
+		return nil, nil, extension_kit.ToError(fmt.Sprintf("Failed to find a configured instance named '%s'", state.InstanceName), err)
+	}
+	targets, err := instance.InWorkspace(state.Workspace).GetUpstreamHealth(ctx, &state.UpstreamId)
+	if err != nil {
+		return nil, nil, extension_kit.ToError(fmt.Sprintf("Failed to get the health of upstream '%s'", state.UpstreamName), err)
+	}
+
+	metrics := make(action_kit_api.Metrics, 0, len(targets))
+	healthy := 0
+	recoveryTargetHealthy := true
+	for _, target := range targets {
+		if target.Target == nil {
+			continue
+		}
+		health := ""
+		if target.Health != nil {
+			health = *target.Health
+		}
+		available := config.IsAvailable(health)
+		if available {
+			healthy++
+		}
+		if *target.Target == state.RecoveryTarget {
+			recoveryTargetHealthy = available
+		}
+		metrics = append(metrics, action_kit_api.Metric{
+			Name: new("kong_upstream_target_health"),
+			Metric: map[string]string{
+				"kong.upstream.name": state.UpstreamName,
+				"target":             *target.Target,
+				"health":             health,
+				"state":              healthWidgetState(health),
+				"tooltip":            fmt.Sprintf("Target %s is %s", *target.Target, health),
+			},
+			Timestamp: now,
+			Value:     boolValue(available),
+		})
+	}
+
+	if len(state.RecoveryTarget) > 0 {
+		if recoveryTargetHealthy {
+			state.UnhealthySince = time.Time{}
+		} else if state.UnhealthySince.IsZero() {
+			state.UnhealthySince = now
+		} else if now.Sub(state.UnhealthySince) > state.RecoveryTimeout {
+			return metrics, &action_kit_api.ActionKitError{
+				Title:  fmt.Sprintf("Target '%s' of upstream '%s' didn't recover within %s.", state.RecoveryTarget, state.UpstreamName, state.RecoveryTimeout),
+				Status: new(action_kit_api.Failed),
+			}, nil
+		}
+	}
+	if healthy < state.MinHealthyTargets {
+		return metrics, &action_kit_api.ActionKitError{
+			Title:  fmt.Sprintf("Only %d of %d targets of upstream '%s' are healthy, expected at least %d.", healthy, len(metrics), state.UpstreamName, state.MinHealthyTargets),
+			Status: new(action_kit_api.Failed),
+		}, nil
+	}
+	return metrics, nil, nil
+}
+
+func healthWidgetState(health string) string {
+	switch health {
+	case config.TargetHealthy:
+		return "success"
+	case config.TargetHealthchecksOff:
+		return "info"
+	default:
+		return "danger"
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package kong
+
+import (
+	"context"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-kong/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// fakeUpstreamHealth serves a service balancing its requests through an upstream with two targets.
+type fakeUpstreamHealth struct {
+	*fakeKongAdmin
+	health map[string]string
+}
+
+func (f *fakeUpstreamHealth) setHealth(target, health string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.health[target] = health
+}
+
+func newFakeUpstreamHealth(t *testing.T) *fakeUpstreamHealth {
+	f := &fakeUpstreamHealth{
+		fakeKongAdmin: newFakeKongAdmin(t),
+		health:        map[string]string{"10.0.0.1:8080": config.TargetHealthy, "10.0.0.2:8080": config.TargetHealthy},
+	}
+	f.handle("GET /services/products", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"id": "products", "name": "products", "host": "products.upstream"})
+	})
+	f.handle("GET /upstreams/products.upstream", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"id": "up-1", "name": "products.upstream"})
+	})
+	f.handle("GET /upstreams/up-1/health", func(w http.ResponseWriter, _ *http.Request) {
+		var data []map[string]any
+		for _, target := range []string{"10.0.0.1:8080", "10.0.0.2:8080"} {
+			data = append(data, map[string]any{"target": target, "health": f.health[target], "weight": 100})
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"data": data, "next": nil})
+	})
+	return f
+}
+
+func prepareUpstreamHealthCheck(serviceId string, actionConfig map[string]any) (*UpstreamHealthCheckState, error) {
+	return prepareAction[UpstreamHealthCheckState](UpstreamHealthCheckAction{}, map[string][]string{
+		"kong.instance.name": {"fake"},
+		"kong.service.id":    {serviceId},
+	}, actionConfig)
+}
+
+func TestUpstreamHealthCheckReportsTargetHealth(t *testing.T) {
+	// Given
+	fake := newFakeUpstreamHealth(t)
+	fake.setHealth("10.0.0.2:8080", config.TargetDnsError)
+	state, err := prepareUpstreamHealthCheck("products", map[string]any{"minHealthyTargets": 1})
+	require.NoError(t, err)
+	assert.Equal(t, "up-1", state.UpstreamId)
+
+	// When
+	result, err := UpstreamHealthCheckAction{}.Status(context.TODO(), state)
+
+	// Then
+	require.NoError(t, err)
+	assert.Nil(t, result.Error)
+	require.Len(t, *result.Metrics, 2)
+	assert.Equal(t, "success", (*result.Metrics)[0].Metric["state"])
+	assert.Equal(t, 1.0, (*result.Metrics)[0].Value)
+	assert.Equal(t, config.TargetDnsError, (*result.Metrics)[1].Metric["health"])
+	assert.Equal(t, "danger", (*result.Metrics)[1].Metric["state"])
+	assert.Equal(t, 0.0, (*result.Metrics)[1].Value)
+}
+
+func TestUpstreamHealthCheckFailsBelowMinHealthyTargets(t *testing.T) {
+	// Given
+	fake := newFakeUpstreamHealth(t)
+	state, err := prepareUpstreamHealthCheck("products", map[string]any{"minHealthyTargets": 2})
+	require.NoError(t, err)
+
+	// When
+	fake.setHealth("10.0.0.1:8080", config.TargetUnhealthy)
+	result, err := UpstreamHealthCheckAction{}.Status(context.TODO(), state)
+
+	// Then
+	require.NoError(t, err)
+	require.NotNil(t, result.Error)
+	assert.Equal(t, action_kit_api.Failed, *result.Error.Status)
+	assert.Equal(t, "Only 1 of 2 targets of upstream 'products.upstream' are healthy, expected at least 2.", result.Error.Title)
+}
+
+func TestUpstreamHealthCheckWaitsForTargetToRecover(t *testing.T) {
+	// Given
+	fake := newFakeUpstreamHealth(t)
+	state, err := prepareUpstreamHealthCheck("products", map[string]any{"minHealthyTargets": 0, "target": "10.0.0.1:8080", "recoveryTimeout": 10000})
+	require.NoError(t, err)
+	now := time.Now()
+
+	// When the target trips and recovers in time
+	fake.setHealth("10.0.0.1:8080", config.TargetUnhealthy)
+	_, checkErr, err := checkUpstreamHealth(context.TODO(), state, now)
+	require.NoError(t, err)
+	assert.Nil(t, checkErr)
+	fake.setHealth("10.0.0.1:8080", config.TargetHealthy)
+	_, checkErr, err = checkUpstreamHealth(context.TODO(), state, now.Add(5*time.Second))
+
+	// Then
+	require.NoError(t, err)
+	assert.Nil(t, checkErr)
+	assert.True(t, state.UnhealthySince.IsZero())
+
+	// When the target stays unhealthy for too long
+	fake.setHealth("10.0.0.1:8080", config.TargetUnhealthy)
+	_, _, _ = checkUpstreamHealth(context.TODO(), state, now.Add(10*time.Second))
+	_, checkErr, err = checkUpstreamHealth(context.TODO(), state, now.Add(21*time.Second))
+
+	// Then
+	require.NoError(t, err)
+	require.NotNil(t, checkErr)
+	assert.Equal(t, "Target '10.0.0.1:8080' of upstream 'products.upstream' didn't recover within 10s.", checkErr.Title)
+
+	stopResult := stopUpstreamHealthCheck(state, now.Add(21*time.Second))
+	require.NotNil(t, stopResult.Error)
+	assert.Equal(t, "Target '10.0.0.1:8080' of upstream 'products.upstream' didn't recover within 10s.", stopResult.Error.Title)
+}
+
+func TestUpstreamHealthCheckStopsWhileTargetIsRecovering(t *testing.T) {
+	// Given
+	fake := newFakeUpstreamHealth(t)
+	state, err := prepareUpstreamHealthCheck("products", map[string]any{"minHealthyTargets": 0, "target": "10.0.0.1:8080", "recoveryTimeout": 10000})
+	require.NoError(t, err)
+	now := time.Now()
+	fake.setHealth("10.0.0.1:8080", config.TargetUnhealthy)
+	_, _, err = checkUpstreamHealth(context.TODO(), state, now)
+	require.NoError(t, err)
+
+	// When the check ends shortly after the target tripped
+	result := stopUpstreamHealthCheck(state, now.Add(3*time.Second))
+
+	// Then
+	require.NotNil(t, result)
+	assert.Nil(t, result.Error)
+	assert.Equal(t, action_kit_api.SummaryLevelWarning, result.Summary.Level)
+	assert.Equal(t, "Target '10.0.0.1:8080' of upstream 'products.upstream' was still recovering when the check ended, unhealthy for 3s.", result.Summary.Text)
+}
+
+func TestUpstreamHealthCheckUsesThePinnedInstance(t *testing.T) {
+	// Given
+	newFakeUpstreamHealth(t)
+	state, err := prepareUpstreamHealthCheck("products", map[string]any{"minHealthyTargets": 1})
+	require.NoError(t, err)
+
+	// When the instance gets removed from the configuration
+	config.SetInstances([]config.Instance{})
+	result, err := UpstreamHealthCheckAction{}.Status(context.TODO(), state)
+
+	// Then
+	require.NoError(t, err)
+	assert.Nil(t, result.Error)
+	_, err = UpstreamHealthCheckAction{}.Stop(context.TODO(), state)
+	require.NoError(t, err)
+	_, err = config.FindInstanceForExecution(state.ExecutionId, "fake")
+	assert.Error(t, err)
+}
+
+func TestPrepareUpstreamHealthCheckRejectsUnknownTargets(t *testing.T) {
+	newFakeUpstreamHealth(t)
+
+	_, err := prepareUpstreamHealthCheck("products", map[string]any{"target": "10.0.0.3:8080"})
+
+	assert.ErrorContains(t, err, "Upstream 'products.upstream' has no target '10.0.0.3:8080'")
+}
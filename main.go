@@ -4,7 +4,11 @@
 package main
 
 import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
 	_ "github.com/KimMachineGun/automemlimit" // By default, it sets `GOMEMLIMIT` to 90% of cgroup's memory limit.
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
@@ -18,7 +22,11 @@ import (
 	"github.com/steadybit/extension-kit/extruntime"
 	"github.com/steadybit/extension-kit/extsignals"
 	"github.com/steadybit/extension-kong/v2/config"
+	"github.com/steadybit/extension-kong/v2/extmetrics"
+	"github.com/steadybit/extension-kong/v2/exttracing"
 	"github.com/steadybit/extension-kong/v2/kong"
+	"net/http"
+	"strings"
 )
 
 func main() {
@@ -33,19 +41,30 @@ func main() {
 	// configuration obtained from environment variables.
 	config.ParseConfiguration()
 	config.ValidateConfiguration()
+	config.WatchInstancesFile(context.Background())
+	if err := exttracing.Init(context.Background()); err != nil {
+		log.Error().Err(err).Msg("Failed to initialize the export of OpenTelemetry traces")
+	}
 
 	discovery_kit_sdk.Register(kong.NewAttributeDescriber())
 	discovery_kit_sdk.Register(kong.NewServiceDiscovery())
-	action_kit_sdk.RegisterAction(kong.NewServiceRequestTerminationAction())
+	action_kit_sdk.RegisterAction(extmetrics.InstrumentAction(kong.NewServiceRequestTerminationAction()))
 	discovery_kit_sdk.Register(kong.NewRouteDiscovery())
-	action_kit_sdk.RegisterAction(kong.NewRequestTerminationAction())
+	action_kit_sdk.RegisterAction(extmetrics.InstrumentAction(kong.NewRequestTerminationAction()))
+	action_kit_sdk.RegisterAction(extmetrics.InstrumentAction(kong.NewHttpCheckAction()))
+	action_kit_sdk.RegisterAction(extmetrics.InstrumentAction(kong.NewMetricsCheckAction()))
+	action_kit_sdk.RegisterAction(extmetrics.InstrumentAction(kong.NewUpstreamHealthCheckAction()))
+	action_kit_sdk.RegisterAction(extmetrics.InstrumentAction(kong.NewServiceDriftCheckAction()))
+	action_kit_sdk.RegisterAction(extmetrics.InstrumentAction(kong.NewDriftCheckAction()))
+	discovery_kit_sdk.Register(kong.NewDataPlaneDiscovery())
+	discovery_kit_sdk.Register(kong.NewNodeDiscovery())
 
 	log.Log().Msgf("Starting with configuration:")
-	for _, instance := range config.Instances {
+	for _, instance := range config.GetInstances() {
 		if instance.IsAuthenticated() {
-			log.Log().Msgf("  %s: %s (authenticated with %s header)", instance.Name, instance.BaseUrl, instance.HeaderKey)
+			log.Log().Msgf("  %s: %s (authenticated with headers %v, basic auth: %t)", instance.Name, instance.Origin(), instance.HeaderNames(), instance.UsesBasicAuth())
 		} else {
-			log.Log().Msgf("  %s: %s", instance.Name, instance.BaseUrl)
+			log.Log().Msgf("  %s: %s", instance.Name, instance.Origin())
 		}
 	}
 	extsignals.ActivateSignalHandlers()
@@ -53,6 +72,11 @@ func main() {
 	exthealth.SetReady(true)
 
 	exthttp.RegisterRevisionedHandler("/", getExtensionList)
+	exthttp.RegisterHttpHandler("/faults", exthttp.GetterAsHandler(kong.GetActiveFaults))
+	if len(config.Config.EmergencyStopToken) > 0 {
+		exthttp.RegisterHttpHandler("/faults/stop", stopAllFaults)
+	}
+	http.Handle("/metrics", promhttp.Handler())
 	exthttp.Listen(exthttp.ListenOpts{
 		Port: 8084,
 	})
@@ -69,3 +93,28 @@ func getExtensionList() ExtensionListResponse {
 		DiscoveryList: discovery_kit_sdk.GetDiscoveryList(),
 	}
 }
+
+// stopAllFaults is the emergency stop for all faults the extension currently injects. Callers authenticate with the
+// configured token. It responds with status 500 if any fault couldn't be stopped.
+func stopAllFaults(w http.ResponseWriter, r *http.Request, _ []byte) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(config.Config.EmergencyStopToken)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	result := kong.StopAllFaults(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	if len(result.Failed) > 0 {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Err(err).Msgf("Failed to write response body")
+	}
+}